@@ -0,0 +1,25 @@
+package rbxver
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// Reads build info. Replaced by tests.
+var readBuildInfo = debug.ReadBuildInfo
+
+// FromBuildInfo parses a version from the version of the main module of the
+// running binary, as reported by debug.ReadBuildInfo. A leading "v" is
+// removed before parsing according to Any. ok is false if build info is
+// unavailable, or the version cannot be parsed.
+func FromBuildInfo() (v Version, ok bool) {
+	info, ok := readBuildInfo()
+	if !ok {
+		return Version{}, false
+	}
+	v, err := parseString(strings.TrimPrefix(info.Main.Version, "v"), Any, 0)
+	if err != nil {
+		return Version{}, false
+	}
+	return v, true
+}
@@ -0,0 +1,34 @@
+package rbxver
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestFromBuildInfo(t *testing.T) {
+	defer func(f func() (*debug.BuildInfo, bool)) { readBuildInfo = f }(readBuildInfo)
+
+	tests := []struct {
+		version string
+		ok      bool
+		v       Version
+	}{
+		{"v0.123.1.1234567", true, Version{0, 123, 1, 1234567, Dot}},
+		{"0.123.1.1234567", true, Version{0, 123, 1, 1234567, Dot}},
+		{"(devel)", false, Version{}},
+		{"v1.2.3", false, Version{}},
+	}
+	for _, test := range tests {
+		readBuildInfo = func() (*debug.BuildInfo, bool) {
+			return &debug.BuildInfo{Main: debug.Module{Version: test.version}}, true
+		}
+		if v, ok := FromBuildInfo(); v != test.v || ok != test.ok {
+			t.Errorf("FromBuildInfo(%q): expected (%v, %t), got (%v, %t)", test.version, test.v, test.ok, v, ok)
+		}
+	}
+
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return nil, false }
+	if v, ok := FromBuildInfo(); v != (Version{}) || ok {
+		t.Errorf("FromBuildInfo(unavailable): expected (%v, %t), got (%v, %t)", Version{}, false, v, ok)
+	}
+}
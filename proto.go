@@ -0,0 +1,74 @@
+package rbxver
+
+import (
+	"math"
+)
+
+// ProtoFormat is the format enum of VersionProto.
+type ProtoFormat int32
+
+const (
+	ProtoFormatAny   ProtoFormat = 0 // Corresponds to Any.
+	ProtoFormatDot   ProtoFormat = 1 // Corresponds to Dot.
+	ProtoFormatComma ProtoFormat = 2 // Corresponds to Comma.
+)
+
+// VersionProto is a plain representation of a Version, suitable for use as a
+// protobuf message.
+type VersionProto struct {
+	Generation int32
+	Version    int32
+	Patch      int32
+	Commit     int32
+	Format     ProtoFormat
+}
+
+// Converts c to an int32, clamped to the range [0, math.MaxInt32].
+func clampInt32(c int) int32 {
+	switch {
+	case c < 0:
+		return 0
+	case c > math.MaxInt32:
+		return math.MaxInt32
+	}
+	return int32(c)
+}
+
+// ToProto returns v as a VersionProto. Components that do not fit in an int32
+// are clamped to math.MaxInt32, and negative components become 0.
+func (v Version) ToProto() *VersionProto {
+	p := &VersionProto{
+		Generation: clampInt32(v.Generation),
+		Version:    clampInt32(v.Version),
+		Patch:      clampInt32(v.Patch),
+		Commit:     clampInt32(v.Commit),
+	}
+	switch v.Format {
+	case Dot:
+		p.Format = ProtoFormatDot
+	case Comma:
+		p.Format = ProtoFormatComma
+	}
+	return p
+}
+
+// FromProto returns p as a Version. Negative components become 0, and an
+// unknown format becomes Any. Returns the zero Version if p is nil.
+func FromProto(p *VersionProto) Version {
+	if p == nil {
+		return Version{}
+	}
+	v := Version{
+		Generation: max(int(p.Generation), 0),
+		Version:    max(int(p.Version), 0),
+		Patch:      max(int(p.Patch), 0),
+		Commit:     max(int(p.Commit), 0),
+	}
+	switch p.Format {
+	case ProtoFormatDot:
+		v.Format = Dot
+	case ProtoFormatComma:
+		v.Format = Comma
+	}
+	return v
+}
@@ -0,0 +1,34 @@
+package rbxver
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProto(t *testing.T) {
+	for _, v := range []Version{
+		{0, 0, 0, 0, Any},
+		{0, 123, 1, 1234567, Dot},
+		{0, 123, 1, 1234567, Comma},
+		{1, math.MaxInt32, 2, 3, Dot},
+	} {
+		p := v.ToProto()
+		if u := FromProto(p); u != v {
+			t.Errorf("FromProto(%v.ToProto()): expected %v, got %v", v, v, u)
+		}
+	}
+
+	v := Version{0, math.MaxInt32 + 1, -1, 1234567, Comma}
+	p := v.ToProto()
+	expected := VersionProto{0, math.MaxInt32, 0, 1234567, ProtoFormatComma}
+	if *p != expected {
+		t.Errorf("%v.ToProto(): expected %+v, got %+v", v, expected, *p)
+	}
+
+	if u := FromProto(&VersionProto{-1, 123, 1, 1234567, 7}); u != (Version{0, 123, 1, 1234567, Any}) {
+		t.Errorf("FromProto(invalid): expected %v, got %v", Version{0, 123, 1, 1234567, Any}, u)
+	}
+	if u := FromProto(nil); u != (Version{}) {
+		t.Errorf("FromProto(nil): expected zero version, got %v", u)
+	}
+}
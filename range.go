@@ -0,0 +1,69 @@
+package rbxver
+
+import (
+	"slices"
+)
+
+// Range represents a range of versions between a lower and upper bound.
+type Range struct {
+	Lo Version // The lower bound.
+	Hi Version // The upper bound. Use Latest for no upper bound.
+
+	LoExclusive bool // Whether Lo is excluded from the range.
+	HiExclusive bool // Whether Hi is excluded from the range.
+}
+
+// Contains returns whether v is semantically within r.
+func (r Range) Contains(v Version) bool {
+	if c := r.Lo.Compare(v); c > 0 || c == 0 && r.LoExclusive {
+		return false
+	}
+	if c := v.Compare(r.Hi); c > 0 || c == 0 && r.HiExclusive {
+		return false
+	}
+	return true
+}
+
+// Compares the lower bounds of a and b, where an inclusive bound is lower than
+// an exclusive bound of the same version.
+func compareLo(a, b Range) int {
+	if c := a.Lo.Compare(b.Lo); c != 0 {
+		return c
+	}
+	switch {
+	case !a.LoExclusive && b.LoExclusive:
+		return -1
+	case a.LoExclusive && !b.LoExclusive:
+		return 1
+	}
+	return 0
+}
+
+// MergeRanges returns the minimal set of non-overlapping ranges covering the
+// same versions as rs, sorted by lower bound. Ranges are merged if they
+// overlap, or if they share a bound that at least one of them includes. rs is
+// not modified.
+func MergeRanges(rs []Range) []Range {
+	if len(rs) == 0 {
+		return nil
+	}
+	rs = slices.Clone(rs)
+	slices.SortStableFunc(rs, compareLo)
+	merged := []Range{rs[0]}
+	for _, r := range rs[1:] {
+		cur := &merged[len(merged)-1]
+		c := r.Lo.Compare(cur.Hi)
+		if c > 0 || c == 0 && r.LoExclusive && cur.HiExclusive {
+			merged = append(merged, r)
+			continue
+		}
+		switch c := r.Hi.Compare(cur.Hi); {
+		case c > 0:
+			cur.Hi = r.Hi
+			cur.HiExclusive = r.HiExclusive
+		case c == 0:
+			cur.HiExclusive = cur.HiExclusive && r.HiExclusive
+		}
+	}
+	return merged
+}
@@ -0,0 +1,96 @@
+package rbxver
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRangeContains(t *testing.T) {
+	r := Range{Lo: Version{0, 123, 0, 0, Dot}, Hi: Version{0, 124, 0, 0, Dot}}
+	rx := r
+	rx.LoExclusive = true
+	rx.HiExclusive = true
+	open := Range{Lo: Version{0, 123, 0, 0, Dot}, Hi: Latest}
+	tests := []struct {
+		r        Range
+		v        Version
+		contains bool
+	}{
+		{r, Version{0, 123, 0, 0, Dot}, true},
+		{r, Version{0, 123, 1, 1234567, Dot}, true},
+		{r, Version{0, 124, 0, 0, Comma}, true},
+		{r, Version{0, 122, 9, 9, Dot}, false},
+		{r, Version{0, 124, 0, 1, Dot}, false},
+		{rx, Version{0, 123, 0, 0, Dot}, false},
+		{rx, Version{0, 123, 0, 1, Dot}, true},
+		{rx, Version{0, 124, 0, 0, Dot}, false},
+		{open, Version{0, 122, 9, 9, Dot}, false},
+		{open, Version{0, 123, 0, 0, Dot}, true},
+		{open, Version{MaxComponent - 1, 0, 0, 0, Dot}, true},
+	}
+	for _, test := range tests {
+		if contains := test.r.Contains(test.v); contains != test.contains {
+			t.Errorf("%v.Contains(%v): expected %t, got %t", test.r, test.v, test.contains, contains)
+		}
+	}
+}
+
+func TestMergeRanges(t *testing.T) {
+	v := func(n int) Version { return Version{0, n, 0, 0, Dot} }
+	tests := []struct {
+		name   string
+		rs     []Range
+		merged []Range
+	}{
+		{
+			name:   "empty",
+			rs:     nil,
+			merged: nil,
+		},
+		{
+			name:   "overlapping",
+			rs:     []Range{{Lo: v(1), Hi: v(5)}, {Lo: v(3), Hi: v(8)}},
+			merged: []Range{{Lo: v(1), Hi: v(8)}},
+		},
+		{
+			name:   "contained",
+			rs:     []Range{{Lo: v(1), Hi: v(8)}, {Lo: v(3), Hi: v(5)}},
+			merged: []Range{{Lo: v(1), Hi: v(8)}},
+		},
+		{
+			name:   "adjacent inclusive",
+			rs:     []Range{{Lo: v(1), Hi: v(3)}, {Lo: v(3), Hi: v(5)}},
+			merged: []Range{{Lo: v(1), Hi: v(5)}},
+		},
+		{
+			name:   "adjacent half-open",
+			rs:     []Range{{Lo: v(1), Hi: v(3), HiExclusive: true}, {Lo: v(3), Hi: v(5)}},
+			merged: []Range{{Lo: v(1), Hi: v(5)}},
+		},
+		{
+			name:   "adjacent exclusive",
+			rs:     []Range{{Lo: v(1), Hi: v(3), HiExclusive: true}, {Lo: v(3), Hi: v(5), LoExclusive: true}},
+			merged: []Range{{Lo: v(1), Hi: v(3), HiExclusive: true}, {Lo: v(3), Hi: v(5), LoExclusive: true}},
+		},
+		{
+			name:   "disjoint",
+			rs:     []Range{{Lo: v(5), Hi: v(8)}, {Lo: v(1), Hi: v(3)}},
+			merged: []Range{{Lo: v(1), Hi: v(3)}, {Lo: v(5), Hi: v(8)}},
+		},
+		{
+			name:   "shared upper bound",
+			rs:     []Range{{Lo: v(1), Hi: v(5), HiExclusive: true}, {Lo: v(2), Hi: v(5)}},
+			merged: []Range{{Lo: v(1), Hi: v(5)}},
+		},
+		{
+			name:   "open",
+			rs:     []Range{{Lo: v(1), Hi: v(3)}, {Lo: v(2), Hi: Latest}, {Lo: v(7), Hi: v(9)}},
+			merged: []Range{{Lo: v(1), Hi: Latest}},
+		},
+	}
+	for _, test := range tests {
+		if merged := MergeRanges(test.rs); !slices.Equal(merged, test.merged) {
+			t.Errorf("MergeRanges(%s): expected %v, got %v", test.name, test.merged, merged)
+		}
+	}
+}
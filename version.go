@@ -3,11 +3,27 @@ package rbxver
 
 import (
 	"bytes"
+	"database/sql/driver"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"hash/fnv"
 	"io"
+	"iter"
+	"log/slog"
+	"math"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 )
 
 // Format determines how a version is parsed and formatted.
@@ -22,6 +38,48 @@ const (
 	Comma
 )
 
+// Flag modifies how a version is parsed. Flags may be combined.
+type Flag uint
+
+const (
+	// Accept a leading '+' on each component.
+	AllowPlus Flag = 1 << iota
+	// With Any, accept either separator at each position independently, rather
+	// than requiring every separator to match the first, such that
+	// "0.123,1.1234567" parses as "0.123.1.1234567". A comma may be followed by
+	// a space or not. The resulting Format is that of the most common
+	// separator. This is lenient, and may accept malformed versions.
+	MixedAny
+	// Treat an empty Generation, Version, or Patch as 0, such that
+	// "0..1.1234567" parses as "0.0.1.1234567". Commit may not be empty.
+	AllowEmpty
+	// Remove invisible code points before parsing. These are U+00AD (soft
+	// hyphen), U+200B to U+200F (zero-width space, non-joiner, joiner, and
+	// directional marks), U+202A to U+202E (directional formatting), U+2060
+	// (word joiner), U+2066 to U+2069 (directional isolates), and U+FEFF
+	// (byte order mark). The number of parsed bytes includes removed code
+	// points.
+	StripInvisible
+	// Require b to contain only the version, such that trailing bytes, including
+	// whitespace, cause ErrSyntax. With Dot, this accepts exactly the form
+	// "N.N.N.N".
+	Strict
+)
+
+// Returns whether r is removed by StripInvisible.
+func isInvisible(r rune) bool {
+	switch {
+	case r == 0x00AD,
+		0x200B <= r && r <= 0x200F,
+		0x202A <= r && r <= 0x202E,
+		r == 0x2060,
+		0x2066 <= r && r <= 0x2069,
+		r == 0xFEFF:
+		return true
+	}
+	return false
+}
+
 // Version represents the version of a Roblox build. Versions can be compared
 // for equality.
 type Version struct {
@@ -34,6 +92,40 @@ type Version struct {
 	Format Format
 }
 
+// Components returns the components of v in the order Generation, Version,
+// Patch, Commit.
+func (v Version) Components() [4]int {
+	return [4]int{v.Generation, v.Version, v.Patch, v.Commit}
+}
+
+// FromComponents returns a Version with components from c, in the order
+// Generation, Version, Patch, Commit, and the given format.
+func FromComponents(c [4]int, f Format) Version {
+	return Version{Generation: c[0], Version: c[1], Patch: c[2], Commit: c[3], Format: f}
+}
+
+// DeltaFrom returns the difference between each component of v and the
+// corresponding component of base, in the order Generation, Version, Patch,
+// Commit. Differences are negative where v is lower than base.
+func (v Version) DeltaFrom(base Version) [4]int {
+	vc, bc := v.Components(), base.Components()
+	var d [4]int
+	for i := range d {
+		d[i] = vc[i] - bc[i]
+	}
+	return d
+}
+
+// ApplyDelta returns v with each difference in d, as returned by DeltaFrom,
+// added to the corresponding component. The Format of v is preserved.
+func (v Version) ApplyDelta(d [4]int) Version {
+	c := v.Components()
+	for i := range c {
+		c[i] += d[i]
+	}
+	return FromComponents(c, v.Format)
+}
+
 // Formats i, writing to b. Writes 0 if i is less than 0.
 func formatInt(b *strings.Builder, i int) {
 	if i <= 0 {
@@ -43,17 +135,140 @@ func formatInt(b *strings.Builder, i int) {
 	b.Write(strconv.AppendInt(nil, int64(i), 10))
 }
 
-// String returns v as a string according to v.Format.
-func (v Version) String() string {
-	var sep string
+// PreferredFormat returns the format that should be used to format v. This is
+// v.Format, or Dot if v.Format is Any or not valid.
+func (v Version) PreferredFormat() Format {
+	if v.Format == Comma {
+		return Comma
+	}
+	return Dot
+}
+
+// Returns the separator between components according to v.Format.
+func (v Version) separator() string {
 	switch v.Format {
 	default:
 		fallthrough
 	case Any, Dot:
-		sep = "."
+		return "."
 	case Comma:
-		sep = ", "
+		return ", "
+	}
+}
+
+// String returns v as a string according to v.Format.
+func (v Version) String() string {
+	sep := v.separator()
+	return v.FormatSeps([3]string{sep, sep, sep})
+}
+
+// Appends i to b, and returns the extended buffer. Appends 0 if i is less than
+// 0.
+func appendInt(b []byte, i int) []byte {
+	return strconv.AppendInt(b, int64(max(i, 0)), 10)
+}
+
+// AppendFormat appends v to b, formatted like String according to f rather
+// than v.Format, and returns the extended buffer.
+func (v Version) AppendFormat(b []byte, f Format) []byte {
+	v.Format = f
+	sep := v.separator()
+	b = appendInt(b, v.Generation)
+	b = append(b, sep...)
+	b = appendInt(b, v.Version)
+	b = append(b, sep...)
+	b = appendInt(b, v.Patch)
+	b = append(b, sep...)
+	return appendInt(b, v.Commit)
+}
+
+// Implements encoding.TextAppender. The version is formatted according to
+// v.Format.
+func (v Version) AppendText(b []byte) ([]byte, error) {
+	return v.AppendFormat(b, v.Format), nil
+}
+
+// Formatter returns a fmt.Formatter that formats v. Version cannot implement
+// fmt.Formatter itself, because its Format field conflicts with the Format
+// method.
+//
+// The verbs %v and %s format v like String, %q formats String as a quoted
+// string, and %d formats the components separated by spaces. %+v formats the
+// components and Format with their field names, and %#v formats v in Go
+// syntax. Width, precision, and the '-' flag apply to the entire result. Other
+// verbs format v as a plain struct.
+func (v Version) Formatter() fmt.Formatter {
+	return formatter{v}
+}
+
+// Implements Version.Formatter.
+type formatter struct {
+	v Version
+}
+
+// Version without methods, formatted by fmt as a plain struct.
+type plainVersion Version
+
+func (ft formatter) Format(f fmt.State, verb rune) {
+	v := ft.v
+	var s string
+	switch verb {
+	case 'v':
+		switch {
+		case f.Flag('#'):
+			s = fmt.Sprintf("rbxver.Version%+v", plainVersion(v))
+			s = strings.ReplaceAll(s, " ", ", ")
+		case f.Flag('+'):
+			s = fmt.Sprintf("%+v", plainVersion(v))
+		default:
+			s = v.String()
+		}
+	case 's':
+		s = v.String()
+	case 'q':
+		s = strconv.Quote(v.String())
+	case 'd':
+		s = fmt.Sprintf("%d %d %d %d", v.Generation, v.Version, v.Patch, v.Commit)
+	default:
+		fmt.Fprintf(f, fmt.FormatString(f, verb), plainVersion(v))
+		return
+	}
+	pad := "%"
+	if f.Flag('-') {
+		pad += "-"
+	}
+	if w, ok := f.Width(); ok {
+		pad += strconv.Itoa(w)
+	}
+	if p, ok := f.Precision(); ok {
+		pad += "." + strconv.Itoa(p)
+	}
+	fmt.Fprintf(f, pad+"s", s)
+}
+
+// Returns the number of bytes written by formatInt for i.
+func intLen(i int) int {
+	n := 1
+	for ; i >= 10; i /= 10 {
+		n++
 	}
+	return n
+}
+
+// EncodedLen returns the length in bytes of the string returned by String,
+// without formatting v.
+func (v Version) EncodedLen() int {
+	n := 3 * len(v.separator())
+	for _, c := range v.Components() {
+		n += intLen(c)
+	}
+	return n
+}
+
+// HexCommitString returns v as a string according to v.Format, with Commit
+// formatted in lowercase hexadecimal, such as "0.123.1.12d687".
+func (v Version) HexCommitString() string {
+	sep := v.separator()
 	var b strings.Builder
 	formatInt(&b, v.Generation)
 	b.WriteString(sep)
@@ -61,6 +276,68 @@ func (v Version) String() string {
 	b.WriteString(sep)
 	formatInt(&b, v.Patch)
 	b.WriteString(sep)
+	b.WriteString(strconv.FormatUint(uint64(max(v.Commit, 0)), 16))
+	return b.String()
+}
+
+// Aligned returns v as a string according to v.Format, with each component
+// right-aligned with spaces to the corresponding width in widths. Components
+// longer than their width are not truncated.
+func (v Version) Aligned(widths [4]int) string {
+	sep := v.separator()
+	var b strings.Builder
+	for i, c := range v.Components() {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		var cb strings.Builder
+		formatInt(&cb, c)
+		for n := cb.Len(); n < widths[i]; n++ {
+			b.WriteByte(' ')
+		}
+		b.WriteString(cb.String())
+	}
+	return b.String()
+}
+
+// The format used by FormatDefault.
+var outputFormat atomic.Int64
+
+// SetOutputFormat sets the format used by FormatDefault. The initial format is
+// Any. SetOutputFormat is safe for concurrent use.
+//
+// Panics if f is not valid format.
+func SetOutputFormat(f Format) {
+	switch f {
+	case Any, Dot, Comma:
+	default:
+		panic("invalid format")
+	}
+	outputFormat.Store(int64(f))
+}
+
+// FormatDefault returns v as a string according to the format set by
+// SetOutputFormat, ignoring v.Format.
+func (v Version) FormatDefault() string {
+	v.Format = Format(outputFormat.Load())
+	return v.String()
+}
+
+// FormatSeps returns v as a string, using each element of seps as the separator
+// between successive components. An empty separator is replaced with ".".
+func (v Version) FormatSeps(seps [3]string) string {
+	for i, sep := range seps {
+		if sep == "" {
+			seps[i] = "."
+		}
+	}
+	var b strings.Builder
+	formatInt(&b, v.Generation)
+	b.WriteString(seps[0])
+	formatInt(&b, v.Version)
+	b.WriteString(seps[1])
+	formatInt(&b, v.Patch)
+	b.WriteString(seps[2])
 	formatInt(&b, v.Commit)
 	return b.String()
 }
@@ -89,138 +366,982 @@ func (v Version) Compare(u Version) int {
 	return 0
 }
 
-// Implements json.Marshaler.
-func (v Version) MarshalJSON() (b []byte, err error) {
-	b = append(b, '"')
-	b = append(b, v.String()...)
-	b = append(b, '"')
-	return b, nil
+// CompareN is like Compare, but compares only the first n components. For
+// example, CompareN(u, 2) compares only Generation and Version.
+//
+// Panics if n is not between 1 and 4.
+func (v Version) CompareN(u Version, n int) int {
+	if n < 1 || n > 4 {
+		panic("invalid level")
+	}
+	return v.Truncate(n).Compare(u.Truncate(n))
 }
 
-// Implements json.Unmarshaler.
-func (v *Version) UnmarshalJSON(b []byte) error {
-	var s string
-	if err := json.Unmarshal(b, &s); err != nil {
-		return err
+// Equal returns whether v is semantically equal to u.
+func (v Version) Equal(u Version) bool {
+	return v.Compare(u) == 0
+}
+
+// Less returns whether v is semantically lower than u.
+func (v Version) Less(u Version) bool {
+	return v.Compare(u) < 0
+}
+
+// LessOrEqual returns whether v is semantically lower than or equal to u.
+func (v Version) LessOrEqual(u Version) bool {
+	return v.Compare(u) <= 0
+}
+
+// Greater returns whether v is semantically higher than u.
+func (v Version) Greater(u Version) bool {
+	return v.Compare(u) > 0
+}
+
+// GreaterOrEqual returns whether v is semantically higher than or equal to u.
+func (v Version) GreaterOrEqual(u Version) bool {
+	return v.Compare(u) >= 0
+}
+
+// Sign returns '<' if v is semantically lower than u, '>' if v is semantically
+// higher than u, and '=' if v is semantically equal to u.
+func (v Version) Sign(u Version) rune {
+	switch v.Compare(u) {
+	case -1:
+		return '<'
+	case 1:
+		return '>'
 	}
-	u, n, err := ParseBytes([]byte(s), v.Format)
-	if err != nil {
-		return err
+	return '='
+}
+
+// StrictlyBetween returns whether v is semantically higher than lo and
+// semantically lower than hi. Returns false if lo is not lower than hi.
+func (v Version) StrictlyBetween(lo, hi Version) bool {
+	return lo.Compare(v) < 0 && v.Compare(hi) < 0
+}
+
+// Clamp returns lo if v is semantically lower than lo, hi if v is semantically
+// higher than hi, and v otherwise.
+//
+// Panics if lo is semantically higher than hi.
+func (v Version) Clamp(lo, hi Version) Version {
+	if lo.Greater(hi) {
+		panic("invalid range: " + lo.String() + " is higher than " + hi.String())
 	}
-	if n != len(s) {
-		return ErrSyntax
+	switch {
+	case v.Less(lo):
+		return lo
+	case v.Greater(hi):
+		return hi
 	}
-	*v = u
-	return nil
+	return v
 }
 
-// Parses an integer from b to comp. Returns false if an error occurred when
-// parsing the integer, or the value is less than 0. b is set to the index after
-// the parsed value.
-func parseInt(comp *int, b *[]byte) bool {
-	i := 0
-	for ; len(*b) > i && '0' <= (*b)[i] && (*b)[i] <= '9'; i++ {
+// SameGeneration returns whether v and u have the same Generation.
+func (v Version) SameGeneration(u Version) bool {
+	return v.Generation == u.Generation
+}
+
+// SameMinor returns whether v and u have the same Generation and Version.
+func (v Version) SameMinor(u Version) bool {
+	return v.Generation == u.Generation && v.Version == u.Version
+}
+
+// InSupportWindow returns whether v is within a rolling support window of the
+// latest minorsBack minor versions before latest. That is, v has the same
+// Generation as latest, and its Version is not higher than that of latest and
+// not lower by more than minorsBack. Patch and Commit are ignored.
+func (v Version) InSupportWindow(latest Version, minorsBack int) bool {
+	return minorsBack >= 0 &&
+		v.Generation == latest.Generation &&
+		v.Version <= latest.Version &&
+		latest.Version-v.Version <= minorsBack
+}
+
+// CrossesGeneration returns whether upgrading from one version to another
+// changes the Generation, which often implies a major migration.
+func CrossesGeneration(from, to Version) bool {
+	return !from.SameGeneration(to)
+}
+
+// CrossesMinor returns whether upgrading from one version to another changes
+// the Generation or Version.
+func CrossesMinor(from, to Version) bool {
+	return !from.SameMinor(to)
+}
+
+// EqualIgnoring returns whether each component of v equals the corresponding
+// component of u, except for the component at index, from 0 for Generation to
+// 3 for Commit.
+//
+// Panics if index is not between 0 and 3.
+func (v Version) EqualIgnoring(u Version, index int) bool {
+	if index < 0 || index > 3 {
+		panic("invalid index")
 	}
-	n, err := strconv.ParseInt(string((*b)[:i]), 10, strconv.IntSize)
-	if err != nil || n < 0 {
-		return false
+	vc := v.Components()
+	uc := u.Components()
+	vc[index], uc[index] = 0, 0
+	return vc == uc
+}
+
+// CommitSpan returns the difference between the Commit of u and the Commit of
+// v. ok is false if the Generation, Version, or Patch of v and u differ, in
+// which case the span is not meaningful.
+func (v Version) CommitSpan(u Version) (span int, ok bool) {
+	if v.Generation != u.Generation || v.Version != u.Version || v.Patch != u.Patch {
+		return 0, false
 	}
-	*comp = int(n)
-	*b = (*b)[i:]
-	return true
+	return u.Commit - v.Commit, true
 }
 
-// Expects sep at the start of b. If *sep is nil, then the separator will be
-// guessed, and sep is set to the guessed separator. b is set to the index after
-// the parsed separator and any whitespace.
-func parseSep(sep *[]byte, b *[]byte) error {
-	if len(*b) < 2 {
-		return io.ErrUnexpectedEOF
+// ChangedComponents returns a bitmask indicating which components differ
+// between v and u. Bit 0 is set if Generation differs, bit 1 if Version
+// differs, bit 2 if Patch differs, and bit 3 if Commit differs.
+func (v Version) ChangedComponents(u Version) uint8 {
+	var m uint8
+	if v.Generation != u.Generation {
+		m |= 1 << 0
 	}
-	if *sep == nil {
-		// Guess separator. This will be used for subsequent separators.
-		switch (*b)[0] {
-		case '.':
-			*sep = (*b)[:1]
-		case ',':
-			if (*b)[1] != ' ' {
-				return ErrSyntax
+	if v.Version != u.Version {
+		m |= 1 << 1
+	}
+	if v.Patch != u.Patch {
+		m |= 1 << 2
+	}
+	if v.Commit != u.Commit {
+		m |= 1 << 3
+	}
+	return m
+}
+
+// LastGood returns the version one commit before v, presumed to be the last
+// good build before v. ok is false if the Commit of v is 0.
+func (v Version) LastGood() (u Version, ok bool) {
+	if v.Commit <= 0 {
+		return v, false
+	}
+	v.Commit--
+	return v, true
+}
+
+// IsClean returns whether the Commit of v is zero, as is the case for some
+// tagged releases.
+func (v Version) IsClean() bool {
+	return v.Commit == 0
+}
+
+// MatchGlob returns whether v matches pattern. The pattern consists of up to
+// four components separated by '.', where each component is either a number
+// that must equal the corresponding component of v, or '*', which matches any
+// value. A '*' may only be followed by other '*' components, and a trailing '*'
+// also matches any omitted components. For example, "0.123.*" matches any
+// version with a Generation of 0 and a Version of 123. Returns ErrSyntax if
+// pattern is invalid.
+func (v Version) MatchGlob(pattern string) (bool, error) {
+	comps, n, err := parseGlob(pattern)
+	if err != nil {
+		return false, err
+	}
+	vc := v.Components()
+	return slices.Equal(vc[:n], comps[:n]), nil
+}
+
+// Parses a pattern as described by MatchGlob. Returns the components of the
+// pattern, and the number of leading components that are not wildcards.
+func parseGlob(pattern string) (comps [4]int, n int, err error) {
+	parts := strings.Split(pattern, ".")
+	if len(parts) > 4 || len(parts) < 4 && parts[len(parts)-1] != "*" {
+		return comps, 0, ErrSyntax
+	}
+	n = len(parts)
+	for i, part := range parts {
+		if part == "*" {
+			if n == len(parts) {
+				n = i
 			}
-			*sep = (*b)[:2]
-		default:
-			return ErrSyntax
+			continue
 		}
-	} else {
-		if len(*b) < len(*sep) {
-			return io.ErrUnexpectedEOF
+		if n < len(parts) {
+			return comps, 0, ErrSyntax
 		}
-		if !bytes.Equal((*b)[:len(*sep)], *sep) {
-			return ErrSyntax
+		c, err := strconv.Atoi(part)
+		if err != nil || c < 0 || part[0] == '+' {
+			return comps, 0, ErrSyntax
 		}
+		comps[i] = c
 	}
-	*b = (*b)[len(*sep):]
-	return nil
+	return comps, n, nil
 }
 
-// ErrSyntax indicates a syntax error while parsing a version string.
-var ErrSyntax = errors.New("invalid syntax")
+// MatchesPattern returns whether re matches the string representation of v.
+func (v Version) MatchesPattern(re *regexp.Regexp) bool {
+	return re.MatchString(v.String())
+}
 
-// ParseBytes parses a version from b according to f.
-//
-// n returns the number of bytes that were parsed from b. Trailing bytes that
-// are not a part of the parsed version do not cause an error.
-//
-// err will be ErrSyntax if the syntax is invalid, or io.ErrUnexpectedEOF if b
-// does not have enough bytes to correctly parse the version. In either case, n
-// will indicate where the error occurred.
+// CompilePattern translates a pattern as described by MatchGlob into a regular
+// expression that matches the string representation of a version in any
+// format. Returns ErrSyntax if pattern is invalid.
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	comps, n, err := parseGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	b.WriteString(`^`)
+	for i, c := range comps {
+		if i > 0 {
+			b.WriteString(`(?:\.|, )`)
+		}
+		if i < n {
+			b.WriteString(strconv.Itoa(c))
+		} else {
+			b.WriteString(`[0-9]+`)
+		}
+	}
+	b.WriteString(`$`)
+	return regexp.Compile(b.String())
+}
+
+// FeatureVector returns the components of v as floating-point numbers, in
+// order of Generation, Version, Patch, and Commit.
+func (v Version) FeatureVector() [4]float64 {
+	return [4]float64{
+		float64(v.Generation),
+		float64(v.Version),
+		float64(v.Patch),
+		float64(v.Commit),
+	}
+}
+
+// NormalizedVector returns the components of v like FeatureVector, with each
+// component divided by the corresponding component of max, and clamped to the
+// range [0, 1]. A component with a max of 0 or less results in 0.
+func (v Version) NormalizedVector(max [4]int) [4]float64 {
+	vec := v.FeatureVector()
+	for i, m := range max {
+		switch {
+		case m <= 0 || vec[i] <= 0:
+			vec[i] = 0
+		case vec[i] >= float64(m):
+			vec[i] = 1
+		default:
+			vec[i] /= float64(m)
+		}
+	}
+	return vec
+}
+
+// Dump returns a multiline representation of v for debugging, with each
+// component and the Format on a separate labeled line. Components are written
+// as-is, including negative values. For example:
 //
-// Panics if f is not valid format.
-func ParseBytes(b []byte, f Format) (v Version, n int, err error) {
-	var sep []byte
-	switch f {
+//	Generation: 0
+//	Version:    123
+//	Patch:      1
+//	Commit:     1234567
+//	Format:     Dot
+func (v Version) Dump() string {
+	var b strings.Builder
+	for i, c := range v.Components() {
+		fmt.Fprintf(&b, "%-11s %d\n", componentNames[i]+":", c)
+	}
+	b.WriteString("Format:     ")
+	switch v.Format {
 	case Any:
+		b.WriteString("Any")
 	case Dot:
-		sep = []byte{'.'}
+		b.WriteString("Dot")
 	case Comma:
-		sep = []byte{',', ' '}
+		b.WriteString("Comma")
 	default:
-		panic("invalid format")
+		fmt.Fprintf(&b, "Format(%d)", int(v.Format))
 	}
+	return b.String()
+}
 
-	l := len(b)
-	if len(b) == 0 {
-		return v, l - len(b), io.ErrUnexpectedEOF
-	}
-	if !parseInt(&v.Generation, &b) {
-		return v, l - len(b), ErrSyntax
-	}
-	if err := parseSep(&sep, &b); err != nil {
-		return v, l - len(b), err
+// Implements slog.LogValuer. The version is logged as a group of its
+// components.
+func (v Version) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("generation", v.Generation),
+		slog.Int("version", v.Version),
+		slog.Int("patch", v.Patch),
+		slog.Int("commit", v.Commit),
+	)
+}
+
+// Implements encoding.TextMarshaler. The version is formatted according to
+// v.Format.
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// Parses the entirety of s according to f, for decoding methods. Returns a
+// *SyntaxError if s is not a complete version, including when s ends early, or
+// an error wrapping ErrOverflow if a component is too large.
+func parseText(s string, f Format) (Version, error) {
+	v, err := parseString(s, f, 0)
+	if err == io.ErrUnexpectedEOF {
+		return Version{}, &SyntaxError{Offset: len(s), Input: s}
 	}
-	if !parseInt(&v.Version, &b) {
-		return v, l - len(b), ErrSyntax
+	return v, err
+}
+
+// Implements encoding.TextUnmarshaler. The version is parsed according to Any,
+// and the detected format is preserved. Returns a *SyntaxError if b is not a
+// complete version, or contains bytes after the version.
+func (v *Version) UnmarshalText(b []byte) error {
+	u, err := parseText(string(b), Any)
+	if err != nil {
+		return err
 	}
-	if err := parseSep(&sep, &b); err != nil {
-		return v, l - len(b), err
+	*v = u
+	return nil
+}
+
+// Implements flag.Value, with String, so that *Version can be used with
+// flag.Var. s is parsed according to Any, and the detected format is
+// preserved. Returns a *SyntaxError if s is not a complete version, or an error
+// wrapping ErrOverflow if a component is too large.
+func (v *Version) Set(s string) error {
+	u, err := parseText(s, Any)
+	if err != nil {
+		return err
 	}
-	if !parseInt(&v.Patch, &b) {
-		return v, l - len(b), ErrSyntax
+	*v = u
+	return nil
+}
+
+// Scanner returns a fmt.Scanner that scans a version into v, such that
+// fmt.Sscanf(line, "build %v done", v.Scanner(Dot)) reads the version from the
+// line. Version cannot implement fmt.Scanner itself, because Scan implements
+// sql.Scanner.
+//
+// The scanner accepts the %v and %s verbs. After skipping leading spaces, it
+// reads digits and separators according to f, stopping at the first rune that
+// cannot continue the version, so that a following ", " or "." is not consumed
+// after Commit. The runes read are then parsed like Parse, returning the error
+// that occurred.
+//
+// Panics if f is not valid format.
+func (v *Version) Scanner(f Format) fmt.Scanner {
+	formatConfig(f, 0)
+	return scanner{v: v, f: f}
+}
+
+// Implements Version.Scanner.
+type scanner struct {
+	v *Version
+	f Format
+}
+
+func (sc scanner) Scan(state fmt.ScanState, verb rune) error {
+	if verb != 'v' && verb != 's' {
+		return fmt.Errorf("bad verb '%%%c' for Version", verb)
 	}
-	if err := parseSep(&sep, &b); err != nil {
-		return v, l - len(b), err
+	state.SkipSpace()
+	var b []byte
+	// Reads the next rune, or returns false at the end of the input.
+	next := func() (rune, bool) {
+		r, _, err := state.ReadRune()
+		return r, err == nil
 	}
-	if !parseInt(&v.Commit, &b) {
-		return v, l - len(b), ErrSyntax
+	for i := 0; i < 4; i++ {
+		if i > 0 {
+			r, ok := next()
+			if !ok {
+				break
+			}
+			if r == '.' && sc.f != Comma {
+				b = append(b, '.')
+			} else if r == ',' && sc.f != Dot {
+				b = append(b, ',')
+				// Anything other than a space is left to cause a syntax error.
+				if r, ok := next(); ok {
+					b = utf8.AppendRune(b, r)
+				}
+			} else {
+				return &SyntaxError{Offset: len(b), Input: string(utf8.AppendRune(b, r))}
+			}
+		}
+		n := len(b)
+		r, ok := next()
+		for ; ok && '0' <= r && r <= '9'; r, ok = next() {
+			b = append(b, byte(r))
+		}
+		if !ok {
+			break
+		}
+		if len(b) == n {
+			return &SyntaxError{Offset: len(b), Input: string(utf8.AppendRune(b, r))}
+		}
+		state.UnreadRune()
 	}
-
-	switch sep[0] {
-	case '.':
-		v.Format = Dot
-	case ',':
-		v.Format = Comma
+	u, err := parseString(string(b), sc.f, 0)
+	if err != nil {
+		return err
 	}
+	*sc.v = u
+	return nil
+}
 
-	return v, l - len(b), nil
+// Implements driver.Valuer. The version is stored as a string according to
+// v.Format.
+func (v Version) Value() (driver.Value, error) {
+	return v.String(), nil
+}
+
+// Implements sql.Scanner. src may be a string or []byte, which is parsed
+// according to Any, or nil, which results in the zero Version. Returns a
+// *SyntaxError if src is not a valid version.
+func (v *Version) Scan(src any) error {
+	var s string
+	switch src := src.(type) {
+	case nil:
+		*v = Version{}
+		return nil
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	default:
+		return fmt.Errorf("cannot scan %T into Version", src)
+	}
+	u, err := parseText(s, Any)
+	if err != nil {
+		return err
+	}
+	*v = u
+	return nil
+}
+
+// Size of the binary encoding of a version.
+const binarySize = 17
+
+// Implements encoding.BinaryMarshaler. The version is encoded as Generation,
+// Version, Patch, and Commit as big-endian 32-bit integers, followed by a byte
+// for Format. Returns an error if a component does not fit in 32 bits.
+func (v Version) MarshalBinary() ([]byte, error) {
+	b := make([]byte, binarySize)
+	for i, c := range v.Components() {
+		if c < 0 || c > math.MaxInt32 {
+			return nil, fmt.Errorf("component %d out of range: %d", i, c)
+		}
+		binary.BigEndian.PutUint32(b[i*4:], uint32(c))
+	}
+	b[16] = byte(v.Format)
+	return b, nil
+}
+
+// Implements encoding.BinaryUnmarshaler, decoding the encoding produced by
+// MarshalBinary. Returns an error if b has the wrong length, a component is
+// negative, or the format is unknown.
+func (v *Version) UnmarshalBinary(b []byte) error {
+	if len(b) != binarySize {
+		return fmt.Errorf("expected %d bytes, got %d", binarySize, len(b))
+	}
+	var u Version
+	for i, comp := range [4]*int{&u.Generation, &u.Version, &u.Patch, &u.Commit} {
+		c := int32(binary.BigEndian.Uint32(b[i*4:]))
+		if c < 0 {
+			return fmt.Errorf("component %d is negative: %d", i, c)
+		}
+		*comp = int(c)
+	}
+	switch f := Format(b[16]); f {
+	case Any, Dot, Comma:
+		u.Format = f
+	default:
+		return fmt.Errorf("unknown format %d", b[16])
+	}
+	*v = u
+	return nil
+}
+
+// Implements json.Marshaler. The version is encoded as a string according to
+// v.Format.
+func (v Version) MarshalJSON() (b []byte, err error) {
+	b = append(b, '"')
+	b = append(b, v.String()...)
+	b = append(b, '"')
+	return b, nil
+}
+
+// Implements json.Unmarshaler. Accepts either a string, parsed according to
+// Any, or an array of four non-negative integers, which results in the Dot
+// format. Returns a *SyntaxError if the string is not a valid version, an error
+// wrapping ErrOverflow if a component of the string is too large, or an error
+// wrapping ErrSyntax if the array is not valid.
+func (v *Version) UnmarshalJSON(b []byte) error {
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '[' {
+		var comps []int
+		if err := json.Unmarshal(b, &comps); err != nil {
+			return err
+		}
+		if len(comps) != 4 {
+			return fmt.Errorf("%w: expected array of 4 components, got %d", ErrSyntax, len(comps))
+		}
+		for _, c := range comps {
+			if c < 0 || c >= MaxComponent {
+				return fmt.Errorf("%w: invalid component %d", ErrSyntax, c)
+			}
+		}
+		*v = Version{comps[0], comps[1], comps[2], comps[3], Dot}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	u, err := parseText(s, Any)
+	if err != nil {
+		return err
+	}
+	*v = u
+	return nil
+}
+
+// VersionArray is a Version that is encoded in JSON as an array of components,
+// such as [0,123,1,1234567], rather than as a string.
+type VersionArray Version
+
+// Implements json.Marshaler. Negative components are encoded as 0.
+func (v VersionArray) MarshalJSON() (b []byte, err error) {
+	b = append(b, '[')
+	for i, c := range Version(v).Components() {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendInt(b, c)
+	}
+	b = append(b, ']')
+	return b, nil
+}
+
+// Implements json.Unmarshaler. Accepts the same values as
+// Version.UnmarshalJSON.
+func (v *VersionArray) UnmarshalJSON(b []byte) error {
+	return (*Version)(v).UnmarshalJSON(b)
+}
+
+// ClientSettingsJSON returns v as a JSON object in the shape expected by Roblox
+// ClientSettings, such as {"version":"0.123.1.1234567"}. The version is always
+// formatted with Dot.
+func (v Version) ClientSettingsJSON() ([]byte, error) {
+	v.Format = Dot
+	return json.Marshal(struct {
+		Version string `json:"version"`
+	}{v.String()})
+}
+
+// Parses an integer from b to comp, the component at index. Returns ErrSyntax if
+// b does not start with an integer, or an error wrapping ErrOverflow that names
+// the component if the value is not less than MaxComponent. b is set to the
+// index after the parsed value. If flags contains AllowPlus, a leading '+' is
+// skipped.
+func parseInt(comp *int, b *[]byte, flags Flag, index int) error {
+	i := 0
+	if flags&AllowPlus != 0 && len(*b) > 0 && (*b)[0] == '+' {
+		i++
+	}
+	start := i
+	// Skip leading zeros so that they do not contribute to the parsed string.
+	for ; len(*b) > i && (*b)[i] == '0'; i++ {
+	}
+	z := i
+	for ; len(*b) > i && '0' <= (*b)[i] && (*b)[i] <= '9'; i++ {
+	}
+	if i == start {
+		return ErrSyntax
+	}
+	var n int64
+	if i > z {
+		var err error
+		n, err = strconv.ParseInt(string((*b)[z:i]), 10, strconv.IntSize)
+		// MaxComponent is reserved so that parsing never produces Latest.
+		if err != nil || n >= MaxComponent {
+			return overflowError(index)
+		}
+	}
+	*comp = int(n)
+	*b = (*b)[i:]
+	return nil
+}
+
+// Names of each component, used in error messages.
+var componentNames = [4]string{"Generation", "Version", "Patch", "Commit"}
+
+// Returns an error wrapping ErrOverflow that names the component at index.
+func overflowError(index int) error {
+	return fmt.Errorf("%w in %s", ErrOverflow, componentNames[index])
+}
+
+// Expects sep at the start of b. If *sep is nil, then the separator will be
+// guessed, and sep is set to the guessed separator. b is set to the index after
+// the parsed separator and any whitespace.
+func parseSep(sep *[]byte, b *[]byte) error {
+	if len(*b) < 2 {
+		return io.ErrUnexpectedEOF
+	}
+	if *sep == nil {
+		// Guess separator. This will be used for subsequent separators.
+		switch (*b)[0] {
+		case '.':
+			*sep = (*b)[:1]
+		case ',':
+			if (*b)[1] != ' ' {
+				return ErrSyntax
+			}
+			*sep = (*b)[:2]
+		default:
+			return ErrSyntax
+		}
+	} else {
+		if len(*b) < len(*sep) {
+			return io.ErrUnexpectedEOF
+		}
+		if !bytes.Equal((*b)[:len(*sep)], *sep) {
+			return ErrSyntax
+		}
+	}
+	*b = (*b)[len(*sep):]
+	return nil
+}
+
+// ErrSyntax indicates a syntax error while parsing a version string.
+var ErrSyntax = errors.New("invalid syntax")
+
+// ErrOverflow indicates that a component of a version string is too large to
+// be represented. Errors returned while parsing wrap ErrOverflow, naming the
+// component that overflowed.
+var ErrOverflow = errors.New("value out of range")
+
+// SyntaxError describes where a syntax error occurred while parsing a version
+// string. It wraps ErrSyntax.
+type SyntaxError struct {
+	Offset int    // Offset in Input of the first invalid byte.
+	Input  string // The string being parsed.
+}
+
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d in %q", ErrSyntax, err.Offset, err.Input)
+}
+
+// Unwrap returns ErrSyntax.
+func (err *SyntaxError) Unwrap() error {
+	return ErrSyntax
+}
+
+// Returns a *SyntaxError at offset n of b if err is ErrSyntax, and err
+// otherwise.
+func syntaxError(err error, b []byte, n int) error {
+	if err == ErrSyntax {
+		return &SyntaxError{Offset: n, Input: string(b)}
+	}
+	return err
+}
+
+// ParseBytes parses a version from b according to f.
+//
+// n returns the number of bytes that were parsed from b. Trailing bytes that
+// are not a part of the parsed version do not cause an error.
+//
+// err will be a *SyntaxError wrapping ErrSyntax if the syntax is invalid, or
+// io.ErrUnexpectedEOF if b does not have enough bytes to correctly parse the
+// version. If a component is too large, err will wrap ErrOverflow, naming the
+// component. In any case, n will indicate where the error occurred.
+//
+// Panics if f is not valid format.
+func ParseBytes(b []byte, f Format) (v Version, n int, err error) {
+	return ParseBytesWith(b, f, 0)
+}
+
+// ParseBytesWith is like ParseBytes, but with flags modifying how the version
+// is parsed.
+func ParseBytesWith(b []byte, f Format, flags Flag) (v Version, n int, err error) {
+	v, _, n, err = ParseBytesConfig(b, formatConfig(f, flags))
+	return v, n, err
+}
+
+// ParseConfig configures how ParseBytesConfig parses a version.
+type ParseConfig struct {
+	// The separator between components, such as "-" or "_". If empty, the
+	// separator is guessed to be "." or ", " like Any.
+	Separator string
+	// Accept any number of spaces before and after each separator.
+	AllowSpaces bool
+	// Flags modifying how the version is parsed.
+	Flags Flag
+}
+
+// FormatConfig returns the ParseConfig equivalent to parsing with f.
+//
+// Panics if f is not valid format.
+func FormatConfig(f Format) ParseConfig {
+	return formatConfig(f, 0)
+}
+
+// Returns the ParseConfig equivalent to parsing with f and flags.
+//
+// Panics if f is not valid format.
+func formatConfig(f Format, flags Flag) ParseConfig {
+	switch f {
+	case Any:
+		return ParseConfig{Flags: flags}
+	case Dot:
+		return ParseConfig{Separator: ".", Flags: flags}
+	case Comma:
+		return ParseConfig{Separator: ", ", Flags: flags}
+	}
+	panic("invalid format")
+}
+
+// ParseBytesConfig is like ParseBytes, but parses according to cfg, and returns
+// sep, the separator that was used. The Format of v is Dot if sep is ".", Comma
+// if sep is ", ", and Any otherwise.
+//
+// Panics if cfg.Separator begins with a digit, '+', or, if cfg.AllowSpaces is
+// set, a space.
+func ParseBytesConfig(b []byte, cfg ParseConfig) (v Version, sep string, n int, err error) {
+	if s := cfg.Separator; s != "" && ('0' <= s[0] && s[0] <= '9' || s[0] == '+' || cfg.AllowSpaces && s[0] == ' ') {
+		panic("invalid separator")
+	}
+	if cfg.Flags&StripInvisible == 0 {
+		v, sep, n, err = parseConfig(b, cfg, nil)
+		return v, sep, n, syntaxError(err, b, n)
+	}
+	// Maps each byte of the stripped input to its offset in b.
+	stripped := make([]byte, 0, len(b))
+	offsets := make([]int, 0, len(b)+1)
+	for i := 0; i < len(b); {
+		r, size := utf8.DecodeRune(b[i:])
+		if !isInvisible(r) {
+			stripped = append(stripped, b[i:i+size]...)
+			for j := 0; j < size; j++ {
+				offsets = append(offsets, i+j)
+			}
+		}
+		i += size
+	}
+	offsets = append(offsets, len(b))
+	v, sep, n, err = parseConfig(stripped, cfg, nil)
+	n = offsets[n]
+	return v, sep, n, syntaxError(err, b, n)
+}
+
+// ParseToken is like ParseBytes, but also returns token, the bytes of b that
+// were consumed while parsing, including when an error occurs.
+//
+// Panics if f is not valid format.
+func ParseToken(b []byte, f Format) (v Version, token string, n int, err error) {
+	v, n, err = ParseBytes(b, f)
+	return v, string(b[:n]), n, err
+}
+
+// ParsePartial is like ParseBytes, but accepts versions with fewer than four
+// components, such as "0.123". Missing trailing components are 0. count is the
+// number of components that were parsed. Parsing stops before a separator that
+// is not followed by a component.
+//
+// If no separator is parsed, the Format is Comma if f is Comma, and Dot
+// otherwise.
+//
+// Panics if f is not valid format.
+func ParsePartial(b []byte, f Format) (v Version, count, n int, err error) {
+	var sep []byte
+	switch f {
+	case Any:
+	case Dot:
+		sep = []byte{'.'}
+	case Comma:
+		sep = []byte{',', ' '}
+	default:
+		panic("invalid format")
+	}
+
+	l := len(b)
+	if len(b) == 0 {
+		return v, 0, 0, io.ErrUnexpectedEOF
+	}
+	for i, comp := range [4]*int{&v.Generation, &v.Version, &v.Patch, &v.Commit} {
+		if i > 0 {
+			next, nextSep := b, sep
+			if parseSep(&nextSep, &next) != nil {
+				break
+			}
+			if err := parseInt(comp, &next, 0, i); err == ErrSyntax {
+				break
+			} else if err != nil {
+				return v, count, l - len(next), err
+			}
+			b, sep = next, nextSep
+		} else if err := parseInt(comp, &b, 0, i); err != nil {
+			return v, 0, 0, syntaxError(err, b, 0)
+		}
+		count++
+	}
+
+	switch {
+	case sep == nil && f == Comma, sep != nil && sep[0] == ',':
+		v.Format = Comma
+	default:
+		v.Format = Dot
+	}
+
+	return v, count, l - len(b), nil
+}
+
+// ParseReader is like ParseBytes, but reads the version from r one byte at a
+// time, parsing the bytes read so far with ParseBytes, and stopping at the
+// first byte that is not a part of the version. n is the number of bytes of the
+// version that were accepted, which is the same as ParseBytes, including when
+// an error occurs. The Input of a *SyntaxError is the bytes that were read from
+// r.
+//
+// Determining the end of the version requires reading the byte after it. If r
+// implements io.ByteScanner, this byte is unread, so that subsequent reads from
+// r begin immediately after the version. Otherwise, the byte is consumed.
+// Errors from r other than io.EOF are returned as is.
+//
+// Panics if f is not valid format.
+func ParseReader(r io.Reader, f Format) (v Version, n int, err error) {
+	scanner, _ := r.(io.ByteScanner)
+	var buf []byte // Bytes that were read from r.
+	for {
+		var c byte
+		if scanner != nil {
+			c, err = scanner.ReadByte()
+		} else {
+			var b [1]byte
+			_, err = io.ReadFull(r, b[:])
+			c = b[0]
+		}
+		if err == io.EOF {
+			return ParseBytes(buf, f)
+		}
+		if err != nil {
+			return v, n, err
+		}
+		buf = append(buf, c)
+		// Read more bytes while the result depends only on the end of buf: a
+		// version that ends there may continue with more digits, and an error
+		// there may be resolved by the next byte.
+		v, n, err = ParseBytes(buf, f)
+		var serr *SyntaxError
+		if err == io.ErrUnexpectedEOF || err == nil && n == len(buf) || errors.As(err, &serr) && serr.Offset == len(buf) {
+			continue
+		}
+		if err == nil && scanner != nil {
+			// Only the last byte is not a part of the version.
+			scanner.UnreadByte()
+		}
+		return v, n, err
+	}
+}
+
+// ParseFunc is like ParseBytes, but calls fn after each component is parsed,
+// with the index of the component, from 0 for Generation to 3 for Commit, and
+// its value. If fn returns a non-nil error, parsing stops, and the error is
+// returned as is, with n indicating the end of the component.
+//
+// Panics if f is not valid format.
+func ParseFunc(b []byte, f Format, fn func(index, value int) error) (v Version, n int, err error) {
+	var fnErr error
+	v, _, n, err = parseConfig(b, formatConfig(f, 0), func(index, value int) error {
+		fnErr = fn(index, value)
+		return fnErr
+	})
+	if fnErr != nil {
+		return v, n, err
+	}
+	return v, n, syntaxError(err, b, n)
+}
+
+// Implements ParseBytesConfig and ParseFunc. fn is called after each component,
+// if it is not nil.
+func parseConfig(b []byte, cfg ParseConfig, fn func(index, value int) error) (v Version, sepStr string, n int, err error) {
+	var sep []byte
+	if cfg.Separator != "" {
+		sep = []byte(cfg.Separator)
+	}
+	flags := cfg.Flags
+
+	mixed := sep == nil && flags&MixedAny != 0
+	var dots int
+
+	skipSpaces := func() {
+		if cfg.AllowSpaces {
+			for len(b) > 0 && b[0] == ' ' {
+				b = b[1:]
+			}
+		}
+	}
+
+	l := len(b)
+	if len(b) == 0 {
+		return v, "", l - len(b), io.ErrUnexpectedEOF
+	}
+	for i, comp := range [4]*int{&v.Generation, &v.Version, &v.Patch, &v.Commit} {
+		if i > 0 {
+			skipSpaces()
+			if mixed {
+				sep = nil
+				if len(b) >= 2 && b[0] == ',' && b[1] != ' ' {
+					// Accept a comma without the following space.
+					sep = b[:1]
+				}
+			}
+			if err := parseSep(&sep, &b); err != nil {
+				return v, "", l - len(b), err
+			}
+			skipSpaces()
+			if sep[0] == '.' {
+				dots++
+			}
+		}
+		var empty bool
+		if flags&AllowEmpty != 0 && i < 3 && len(b) > 0 {
+			if cfg.Separator == "" {
+				empty = b[0] == '.' || b[0] == ','
+			} else {
+				empty = b[0] == cfg.Separator[0]
+			}
+		}
+		if empty {
+			// Empty component is left as 0.
+		} else if err := parseInt(comp, &b, flags, i); err != nil {
+			return v, "", l - len(b), err
+		}
+		if fn != nil {
+			if err := fn(i, *comp); err != nil {
+				return v, "", l - len(b), err
+			}
+		}
+	}
+
+	if flags&Strict != 0 && len(b) > 0 {
+		return v, "", l - len(b), ErrSyntax
+	}
+
+	if mixed {
+		// There are three separators, so the majority is never tied.
+		if dots >= 2 {
+			v.Format = Dot
+			return v, ".", l - len(b), nil
+		}
+		v.Format = Comma
+		return v, ", ", l - len(b), nil
+	}
+
+	switch string(sep) {
+	case ".":
+		v.Format = Dot
+	case ", ":
+		v.Format = Comma
+	}
+
+	return v, string(sep), l - len(b), nil
 }
 
 // Parse parses s as a version string according to f. Returns the zero value if
@@ -229,8 +1350,1229 @@ func ParseBytes(b []byte, f Format) (v Version, n int, err error) {
 //
 // Panics if f is not valid format.
 func Parse(s string, f Format) Version {
-	if v, n, err := ParseBytes([]byte(s), f); err == nil && n == len(s) {
+	return ParseWith(s, f, 0)
+}
+
+// ParseWith is like Parse, but with flags modifying how the version is parsed.
+func ParseWith(s string, f Format, flags Flag) Version {
+	if v, err := parseString(s, f, flags); err == nil {
 		return v
 	}
 	return Version{}
 }
+
+// MustParse is like Parse, but panics if s cannot be parsed. The panic message
+// includes s and the error that occurred. Simplifies initialization of
+// variables holding known versions.
+func MustParse(s string, f Format) Version {
+	v, err := parseString(s, f, 0)
+	if err != nil {
+		panic("rbxver: MustParse(" + strconv.Quote(s) + "): " + err.Error())
+	}
+	return v
+}
+
+// Parses the entirety of s according to f and flags. Returns a *SyntaxError if
+// the syntax is invalid or s has trailing bytes.
+func parseString(s string, f Format, flags Flag) (Version, error) {
+	v, n, err := ParseBytesWith([]byte(s), f, flags)
+	if err == nil && n != len(s) {
+		return Version{}, &SyntaxError{Offset: n, Input: s}
+	}
+	if err != nil {
+		return Version{}, err
+	}
+	return v, nil
+}
+
+// ParseImplicitGen parses s as a version string according to f. If s contains
+// only three components, they are assigned to Version, Patch, and Commit, and
+// Generation is 0. Otherwise, s is parsed like Parse, returning the error that
+// occurred.
+//
+// Panics if f is not valid format.
+func ParseImplicitGen(s string, f Format) (Version, error) {
+	v, err := parseString(s, f, 0)
+	if err == nil {
+		return v, nil
+	}
+	var prefixes []string
+	switch f {
+	case Any:
+		prefixes = []string{"0.", "0, "}
+	case Dot:
+		prefixes = []string{"0."}
+	case Comma:
+		prefixes = []string{"0, "}
+	}
+	for _, prefix := range prefixes {
+		if v, err := parseString(prefix+s, f, 0); err == nil {
+			return v, nil
+		}
+	}
+	return Version{}, err
+}
+
+// Verify parses s as a version string according to f, and checks that it is
+// semantically equal to expected. Returns an error wrapping ErrMismatch if the
+// versions differ, or the error that occurred while parsing s.
+//
+// Panics if f is not valid format.
+func Verify(s string, expected Version, f Format) error {
+	v, err := parseString(s, f, 0)
+	if err != nil {
+		return err
+	}
+	if v.Compare(expected) != 0 {
+		return fmt.Errorf("%w: expected %v, got %v", ErrMismatch, expected, v)
+	}
+	return nil
+}
+
+// ParseShell parses s as a version string according to f, after removing a
+// single pair of surrounding single or double quotes, as may appear in shell
+// output. Returns a *SyntaxError if the quotes are unbalanced.
+//
+// Panics if f is not valid format.
+func ParseShell(s string, f Format) (Version, error) {
+	if len(s) > 0 && (s[0] == '\'' || s[0] == '"') {
+		if len(s) < 2 || s[len(s)-1] != s[0] {
+			return Version{}, &SyntaxError{Offset: 0, Input: s}
+		}
+		v, err := parseString(s[1:len(s)-1], f, 0)
+		var serr *SyntaxError
+		if errors.As(err, &serr) {
+			// Report the offset within the quoted string.
+			return Version{}, &SyntaxError{Offset: serr.Offset + 1, Input: s}
+		}
+		return v, err
+	}
+	if len(s) > 0 && (s[len(s)-1] == '\'' || s[len(s)-1] == '"') {
+		return Version{}, &SyntaxError{Offset: len(s) - 1, Input: s}
+	}
+	return parseString(s, f, 0)
+}
+
+// ParseTruncate parses s as a version string according to f, then sets each
+// component after the first level components to 0.
+//
+// Panics if f is not valid format, or if level is not between 1 and 4.
+func ParseTruncate(s string, f Format, level int) (Version, error) {
+	if level < 1 || level > 4 {
+		panic("invalid level")
+	}
+	v, err := parseString(s, f, 0)
+	if err != nil {
+		return Version{}, err
+	}
+	return v.Truncate(level), nil
+}
+
+// ParseBounded parses s as a version string according to f, and checks that
+// each component has no more digits than the corresponding limit in maxDigits.
+// Leading zeros are counted. A limit less than 1 is not checked. Returns an
+// error wrapping ErrOverflow, naming the component, if a component exceeds its
+// limit, or the error that occurred while parsing s.
+//
+// Panics if f is not valid format.
+func ParseBounded(s string, f Format, maxDigits [4]int) (Version, error) {
+	v, err := parseString(s, f, 0)
+	if err != nil {
+		return Version{}, err
+	}
+	// Components are the only runs of digits in a parsed version.
+	i := 0
+	for c := 0; c < 4; c++ {
+		for s[i] < '0' || '9' < s[i] {
+			i++
+		}
+		start := i
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+		if maxDigits[c] > 0 && i-start > maxDigits[c] {
+			return Version{}, fmt.Errorf("%w in %s: more than %d digits", ErrOverflow, componentNames[c], maxDigits[c])
+		}
+	}
+	return v, nil
+}
+
+// Integer is satisfied by any integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// ParseGeneric parses s as a version string according to f, returning each
+// component as T. Returns an error wrapping ErrOverflow, naming the component,
+// if a component cannot be represented by T, or the error that occurred while
+// parsing s.
+//
+// Panics if f is not valid format.
+func ParseGeneric[T Integer](s string, f Format) (gen, ver, patch, commit T, err error) {
+	v, err := parseString(s, f, 0)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	var c [4]T
+	for i, comp := range v.Components() {
+		c[i] = T(comp)
+		if int(c[i]) != comp {
+			return 0, 0, 0, 0, overflowError(i)
+		}
+	}
+	return c[0], c[1], c[2], c[3], nil
+}
+
+// NormalizeBatch parses each string in ss according to Any, and formats it
+// according to out. The returned slices have the same length as ss. For each
+// string that fails to parse, the result is empty and the error is set.
+// Otherwise, the error is nil.
+func NormalizeBatch(ss []string, out Format) ([]string, []error) {
+	results := make([]string, len(ss))
+	errs := make([]error, len(ss))
+	for i, s := range ss {
+		v, err := parseString(s, Any, 0)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		v.Format = out
+		results[i] = v.String()
+	}
+	return results, errs
+}
+
+// Returns whether s is a GUID of the form
+// "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx", where each x is a hexadecimal digit.
+func isGUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+			continue
+		}
+		if !('0' <= s[i] && s[i] <= '9' || 'a' <= s[i] && s[i] <= 'f' || 'A' <= s[i] && s[i] <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseGUIDSuffixed parses a version followed by a braced GUID, separated by an
+// underscore, such as "0.123.1.1234567_{01234567-89ab-cdef-0123-456789abcdef}".
+// The version is parsed according to Any, and guid is returned with its
+// braces. Returns an error wrapping ErrSyntax if s has no GUID suffix, or if
+// the GUID is malformed, or the error that occurred while parsing the version.
+func ParseGUIDSuffixed(s string) (v Version, guid string, err error) {
+	i := strings.Index(s, "_{")
+	if i < 0 {
+		return Version{}, "", fmt.Errorf("%w: missing GUID suffix", ErrSyntax)
+	}
+	guid = s[i+1:]
+	if guid[len(guid)-1] != '}' || !isGUID(guid[1:len(guid)-1]) {
+		return Version{}, "", fmt.Errorf("%w: malformed GUID %q", ErrSyntax, guid)
+	}
+	if v, err = parseString(s[:i], Any, 0); err != nil {
+		return Version{}, "", err
+	}
+	return v, guid, nil
+}
+
+// ParseStringer parses the result of s.String() as a version string according
+// to f.
+//
+// Panics if f is not valid format.
+func ParseStringer(s fmt.Stringer, f Format) (Version, error) {
+	return parseString(s.String(), f, 0)
+}
+
+// ParseHexCommit parses s as a version string according to f, in the form
+// returned by HexCommitString, where Commit is hexadecimal. Returns a
+// *SyntaxError if s is not in that form.
+//
+// Panics if f is not valid format.
+func ParseHexCommit(s string, f Format) (Version, error) {
+	var sep string
+	switch f {
+	case Any:
+		sep = "."
+		if strings.Contains(s, ",") {
+			sep = ", "
+		}
+	case Dot:
+		sep = "."
+	case Comma:
+		sep = ", "
+	default:
+		panic("invalid format")
+	}
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return Version{}, &SyntaxError{Offset: len(s), Input: s}
+	}
+	hex := s[i+len(sep):]
+	if hex == "" || hex[0] == '+' || hex[0] == '-' {
+		return Version{}, &SyntaxError{Offset: i + len(sep), Input: s}
+	}
+	commit, err := strconv.ParseInt(hex, 16, strconv.IntSize)
+	if err != nil || commit >= MaxComponent {
+		return Version{}, &SyntaxError{Offset: i + len(sep), Input: s}
+	}
+	// Parse the decimal components with a placeholder Commit.
+	v, err := parseString(s[:i+len(sep)]+"0", f, 0)
+	var serr *SyntaxError
+	if errors.As(err, &serr) {
+		// Offsets before the placeholder are the same in s.
+		return Version{}, &SyntaxError{Offset: serr.Offset, Input: s}
+	}
+	if err != nil {
+		return Version{}, err
+	}
+	v.Commit = int(commit)
+	return v, nil
+}
+
+// ParseAudit parses s as a version string according to f, recovering from
+// minor issues. Each recovered issue is described by a warning. The following
+// issues are recovered:
+//
+//   - Leading or trailing whitespace is trimmed.
+//   - The separator is guessed when f is Any.
+//   - Components with leading zeros are accepted.
+//
+// Returns an error if s cannot be parsed after recovery.
+//
+// Panics if f is not valid format.
+func ParseAudit(s string, f Format) (Version, []string, error) {
+	var warnings []string
+	if t := strings.TrimLeft(s, " \t\r\n"); len(t) != len(s) {
+		warnings = append(warnings, "trimmed leading whitespace")
+		s = t
+	}
+	if t := strings.TrimRight(s, " \t\r\n"); len(t) != len(s) {
+		warnings = append(warnings, "trimmed trailing whitespace")
+		s = t
+	}
+	v, err := parseString(s, f, 0)
+	if err != nil {
+		return Version{}, warnings, err
+	}
+	if f == Any {
+		switch v.Format {
+		case Dot:
+			warnings = append(warnings, "guessed dot separator")
+		case Comma:
+			warnings = append(warnings, "guessed comma separator")
+		}
+	}
+	for i, part := range strings.Split(strings.ReplaceAll(s, ", ", "."), ".") {
+		if len(part) > 1 && part[0] == '0' {
+			warnings = append(warnings, "accepted leading zeros in "+strings.ToLower(componentNames[i]))
+		}
+	}
+	return v, warnings, nil
+}
+
+// ParseAnyOf parses s as a version string according to each format in order,
+// returning the first version that parses successfully. If no format succeeds,
+// the error from the last format is returned. Returns ErrSyntax if no formats
+// are given.
+//
+// Panics if a format is not valid.
+func ParseAnyOf(s string, formats ...Format) (Version, error) {
+	err := ErrSyntax
+	for _, f := range formats {
+		var v Version
+		if v, err = parseString(s, f, 0); err == nil {
+			return v, nil
+		}
+	}
+	return Version{}, err
+}
+
+// Coerce returns v with each component clamped to the corresponding component
+// of max, in order of Generation, Version, Patch, and Commit.
+func (v Version) Coerce(max [4]int) Version {
+	if v.Generation > max[0] {
+		v.Generation = max[0]
+	}
+	if v.Version > max[1] {
+		v.Version = max[1]
+	}
+	if v.Patch > max[2] {
+		v.Patch = max[2]
+	}
+	if v.Commit > max[3] {
+		v.Commit = max[3]
+	}
+	return v
+}
+
+// Characters used by DisplayHint.
+const hintChars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// DisplayHint returns a short token derived from the components of v, useful
+// for visually identifying a version. The same components always produce the
+// same token, regardless of Format. Different versions may produce the same
+// token.
+func (v Version) DisplayHint() string {
+	var b [32]byte
+	binary.BigEndian.PutUint64(b[0:], uint64(v.Generation))
+	binary.BigEndian.PutUint64(b[8:], uint64(v.Version))
+	binary.BigEndian.PutUint64(b[16:], uint64(v.Patch))
+	binary.BigEndian.PutUint64(b[24:], uint64(v.Commit))
+	h := fnv.New32a()
+	h.Write(b[:])
+	sum := h.Sum32()
+	return string([]byte{
+		hintChars[sum%uint32(len(hintChars))],
+		hintChars[sum/uint32(len(hintChars))%uint32(len(hintChars))],
+	})
+}
+
+// ErrNoField indicates that a field could not be found in a JSON object.
+var ErrNoField = errors.New("field not found")
+
+// ParseJSONField parses a version from the string value of the top-level field
+// of the JSON object in data, according to f. Other values in the object are
+// skipped without being decoded.
+//
+// Returns ErrNoField if the object does not contain the field. Returns a
+// *SyntaxError if data is not an object, or if the value is not a string
+// containing only a version.
+//
+// Panics if f is not valid format.
+func ParseJSONField(data []byte, field string, f Format) (Version, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return Version{}, err
+	}
+	if tok != json.Delim('{') {
+		return Version{}, &SyntaxError{Offset: jsonTokenOffset(data, 0), Input: string(data)}
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return Version{}, err
+		}
+		if key, _ := tok.(string); key != field {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return Version{}, err
+			}
+			continue
+		}
+		off := dec.InputOffset()
+		tok, err = dec.Token()
+		if err != nil {
+			return Version{}, err
+		}
+		s, ok := tok.(string)
+		if !ok {
+			return Version{}, &SyntaxError{Offset: jsonTokenOffset(data, off), Input: string(data)}
+		}
+		return parseString(s, f, 0)
+	}
+	return Version{}, ErrNoField
+}
+
+// Returns the offset of the first JSON token in data at or after offset n,
+// skipping whitespace and the separators between tokens.
+func jsonTokenOffset(data []byte, n int64) int {
+	i := int(n)
+	for i < len(data) && strings.IndexByte(" \t\r\n:,", data[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+// Pool holds *Version values for reuse. Values should be retrieved with
+// AcquireVersion and returned with ReleaseVersion. Like any sync.Pool, Pool is
+// safe for concurrent use, but a Version retrieved from it is not.
+var Pool = sync.Pool{
+	New: func() any { return new(Version) },
+}
+
+// AcquireVersion retrieves a zero Version from Pool.
+func AcquireVersion() *Version {
+	return Pool.Get().(*Version)
+}
+
+// ReleaseVersion zeros v and returns it to Pool. v must not be used after being
+// released.
+func ReleaseVersion(v *Version) {
+	if v == nil {
+		return
+	}
+	*v = Version{}
+	Pool.Put(v)
+}
+
+// MaxComponent is the largest value of a version component. Parsed components
+// are always less than MaxComponent, so that it can represent an unbounded
+// component, such as in Latest.
+const MaxComponent = math.MaxInt
+
+// Latest is a sentinel version that is semantically higher than any other
+// version, representing the absence of an upper bound. Parsing never produces
+// Latest, because a parsed component is always less than MaxComponent.
+var Latest = Version{
+	Generation: MaxComponent,
+	Version:    MaxComponent,
+	Patch:      MaxComponent,
+	Commit:     MaxComponent,
+	Format:     Dot,
+}
+
+// Truncate returns v with the first n components kept, and the remaining
+// components set to 0. For example, Truncate(2) on 12.34.56.78 results in
+// 12.34.0.0. The Format of v is preserved.
+//
+// Panics if n is not between 0 and 4.
+func (v Version) Truncate(n int) Version {
+	if n < 0 || n > 4 {
+		panic("invalid level")
+	}
+	c := [4]*int{&v.Generation, &v.Version, &v.Patch, &v.Commit}
+	for i := n; i < len(c); i++ {
+		*c[i] = 0
+	}
+	return v
+}
+
+// Zero is the zero Version, which is returned by parsers when a version could
+// not be parsed.
+var Zero = Version{}
+
+// IsZero returns whether each component of v is 0, ignoring Format. Unlike
+// comparing v with Zero, this is true for a successfully parsed "0.0.0.0".
+func (v Version) IsZero() bool {
+	return v.Generation == 0 && v.Version == 0 && v.Patch == 0 && v.Commit == 0
+}
+
+// PredecessorAt returns the largest parsable version strictly below v after v
+// is truncated to the first level components. Components below level are set
+// to MaxComponent-1, the largest value that can be parsed. For example, at
+// level 2, 0.124.5.6 results in 0.123.M.M, where M is MaxComponent-1.
+// Decrementing a zero component borrows from the component above it. The
+// Format of v is preserved. ok is false if no lower version exists, such as
+// for any version with a Generation of 0 at level 1.
+//
+// Panics if level is not between 1 and 4.
+func (v Version) PredecessorAt(level int) (u Version, ok bool) {
+	if level < 1 || level > 4 {
+		panic("invalid level")
+	}
+	c := [4]*int{&v.Generation, &v.Version, &v.Patch, &v.Commit}
+	for i := level; i < len(c); i++ {
+		*c[i] = MaxComponent - 1
+	}
+	for i := level - 1; i >= 0; i-- {
+		if *c[i] > 0 {
+			*c[i]--
+			return v, true
+		}
+		*c[i] = MaxComponent - 1
+	}
+	return Version{}, false
+}
+
+// Returns whether s is a Roblox version hash, such as
+// "version-0123456789abcdef".
+func isHash(s string) bool {
+	const prefix = "version-"
+	if !strings.HasPrefix(s, prefix) || len(s) != len(prefix)+16 {
+		return false
+	}
+	for _, c := range []byte(s[len(prefix):]) {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrHash indicates that a version hash, such as "version-0123456789abcdef",
+// was found where a version string was expected.
+var ErrHash = errors.New("version hash is not a version string")
+
+// ParseRegistryValue parses a version from a Windows registry value. s may be
+// the data of the value, optionally surrounded by double quotes, or a line of
+// "reg query" output such as "    version    REG_SZ    0.123.1.1234567", in
+// which case the data after REG_SZ is used. Surrounding whitespace is ignored.
+// The data is parsed according to Any.
+//
+// Returns an error wrapping ErrHash if the data has the "version-" prefix of a
+// version hash, which cannot be converted to a version, even if the hash is
+// malformed. Otherwise, returns the error that occurred while parsing the data.
+func ParseRegistryValue(s string) (Version, error) {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "REG_SZ"); i >= 0 {
+		s = strings.TrimSpace(s[i+len("REG_SZ"):])
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	if strings.HasPrefix(s, "version-") {
+		return Version{}, fmt.Errorf("%w: %s", ErrHash, s)
+	}
+	return parseString(s, Any, 0)
+}
+
+// Style indicates the kind of string detected by DetectStyle.
+type Style int
+
+const (
+	// Not recognized.
+	StyleNone Style = iota
+	// A version with dot separators, such as `0.123.1.1234567`.
+	StyleDot
+	// A version with comma separators, such as `0, 123, 1, 1234567`.
+	StyleComma
+	// A version hash, such as `version-0123456789abcdef`.
+	StyleHash
+)
+
+// DetectStyle classifies s by inspecting its characters, without fully parsing
+// it. A string detected as StyleDot or StyleComma is not guaranteed to parse
+// successfully.
+func DetectStyle(s string) Style {
+	if isHash(s) {
+		return StyleHash
+	}
+	if len(s) == 0 || s[0] < '0' || '9' < s[0] {
+		return StyleNone
+	}
+	var dots, commas, spaces int
+	for _, c := range []byte(s) {
+		switch {
+		case '0' <= c && c <= '9':
+		case c == '.':
+			dots++
+		case c == ',':
+			commas++
+		case c == ' ':
+			spaces++
+		default:
+			return StyleNone
+		}
+	}
+	switch {
+	case dots == 3 && commas == 0 && spaces == 0:
+		return StyleDot
+	case dots == 0 && commas == 3 && spaces == 3:
+		return StyleComma
+	}
+	return StyleNone
+}
+
+// Describe classifies s. kind is "version" if s is a version string of any
+// format, "hash" if s is a version hash such as "version-0123456789abcdef", or
+// "neither" otherwise. When kind is "neither", reason is a human-readable
+// description of why s could not be parsed as a version.
+func Describe(s string) (kind string, reason string) {
+	_, n, err := ParseBytes([]byte(s), Any)
+	switch {
+	case err == nil && n == len(s):
+		return "version", ""
+	case isHash(s):
+		return "hash", ""
+	case err == nil:
+		return "neither", fmt.Sprintf("unexpected %q at position %d", s[n], n)
+	case err == io.ErrUnexpectedEOF:
+		return "neither", fmt.Sprintf("unexpected end of string at position %d", n)
+	case errors.Is(err, ErrOverflow):
+		return "neither", fmt.Sprintf("number too large at position %d", n)
+	}
+	// Components are preceded by nothing or a separator.
+	if n == 0 || s[n-1] == '.' || s[n-1] == ' ' {
+		return "neither", fmt.Sprintf("expected digit at position %d", n)
+	}
+	switch i := strings.IndexAny(s[:n], ".,"); {
+	case i < 0:
+		return "neither", fmt.Sprintf("expected '.' or ', ' separator at position %d", n)
+	case s[i] == '.':
+		return "neither", fmt.Sprintf("expected '.' separator at position %d", n)
+	default:
+		return "neither", fmt.Sprintf("expected ', ' separator at position %d", n)
+	}
+}
+
+// ErrMismatch indicates that versions differ in components that were required
+// to be equal.
+var ErrMismatch = errors.New("mismatched components")
+
+// Midpoint returns a version with the Generation, Version, Patch, and Format
+// of a, and a Commit that is the average of the Commits of a and b, rounded
+// down. Returns ErrMismatch if the Generation, Version, or Patch of a and b
+// differ.
+func Midpoint(a, b Version) (Version, error) {
+	if a.Generation != b.Generation || a.Version != b.Version || a.Patch != b.Patch {
+		return Version{}, ErrMismatch
+	}
+	lo, hi := a.Commit, b.Commit
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	a.Commit = lo + (hi-lo)/2
+	return a, nil
+}
+
+// Bisect searches the Commits from lo to hi, inclusive, for the boundary where
+// test changes from true to false, assuming test is true for every version
+// before the boundary and false for every version after. Returns the last
+// version for which test is true, with the Format of lo. Returns false if test
+// is false for lo, if lo is higher than hi, or if the Generation, Version, or
+// Patch of lo and hi differ.
+func Bisect(lo, hi Version, test func(Version) bool) (Version, bool) {
+	if lo.Generation != hi.Generation || lo.Version != hi.Version || lo.Patch != hi.Patch || lo.Commit > hi.Commit {
+		return Version{}, false
+	}
+	if !test(lo) {
+		return Version{}, false
+	}
+	// test is true for a, and the boundary is between a and b. The distance is
+	// computed as unsigned so that it cannot overflow.
+	v := lo
+	a, b := lo.Commit, hi.Commit
+	for a < b {
+		d := uint(b) - uint(a)
+		v.Commit = a + int(d/2+d%2)
+		if test(v) {
+			a = v.Commit
+		} else {
+			b = v.Commit - 1
+		}
+	}
+	v.Commit = a
+	return v, true
+}
+
+// Max returns the semantically highest version in vs. If several versions are
+// equally highest, the first is returned. Returns the zero Version if vs is
+// empty.
+func Max(vs ...Version) Version {
+	var m Version
+	for i, v := range vs {
+		if i == 0 || v.Greater(m) {
+			m = v
+		}
+	}
+	return m
+}
+
+// Min returns the semantically lowest version in vs. If several versions are
+// equally lowest, the first is returned. Returns the zero Version if vs is
+// empty.
+func Min(vs ...Version) Version {
+	var m Version
+	for i, v := range vs {
+		if i == 0 || v.Less(m) {
+			m = v
+		}
+	}
+	return m
+}
+
+// Versions implements sort.Interface, ordering versions semantically with
+// Compare.
+type Versions []Version
+
+func (vs Versions) Len() int           { return len(vs) }
+func (vs Versions) Less(i, j int) bool { return vs[i].Compare(vs[j]) < 0 }
+func (vs Versions) Swap(i, j int)      { vs[i], vs[j] = vs[j], vs[i] }
+
+// Sort sorts vs in place in ascending semantic order.
+func Sort(vs []Version) {
+	sort.Sort(Versions(vs))
+}
+
+// ManifestError describes a version in a manifest that failed validation.
+type ManifestError struct {
+	Index  int    // Index of the invalid version.
+	Reason string // Description of why the version is invalid.
+}
+
+func (err *ManifestError) Error() string {
+	return fmt.Sprintf("version %d: %s", err.Index, err.Reason)
+}
+
+// ValidateManifest checks that each version in vs is semantically higher than
+// the previous version, and that each version has the format requireFormat.
+// Returns a *ManifestError describing the first violation, or nil if vs is
+// valid.
+func ValidateManifest(vs []Version, requireFormat Format) error {
+	for i, v := range vs {
+		if v.Format != requireFormat {
+			return &ManifestError{Index: i, Reason: fmt.Sprintf("format %d does not match required format %d", v.Format, requireFormat)}
+		}
+		if i > 0 && !v.Greater(vs[i-1]) {
+			return &ManifestError{Index: i, Reason: fmt.Sprintf("%v is not higher than %v", v, vs[i-1])}
+		}
+	}
+	return nil
+}
+
+// FindGaps returns each pair of adjacent versions in vs where a build may be
+// missing. vs is expected to be sorted in ascending order. For each pair, the
+// most significant component that differs is compared, and the pair is a gap
+// if that component increases by more than one.
+func FindGaps(vs []Version) [][2]Version {
+	var gaps [][2]Version
+	for i := 1; i < len(vs); i++ {
+		a, b := vs[i-1], vs[i]
+		var d int
+		switch {
+		case a.Generation != b.Generation:
+			d = b.Generation - a.Generation
+		case a.Version != b.Version:
+			d = b.Version - a.Version
+		case a.Patch != b.Patch:
+			d = b.Patch - a.Patch
+		default:
+			d = b.Commit - a.Commit
+		}
+		if d > 1 {
+			gaps = append(gaps, [2]Version{a, b})
+		}
+	}
+	return gaps
+}
+
+// Coalesce returns fallback if v.IsZero, and v otherwise.
+func Coalesce(v, fallback Version) Version {
+	if v.IsZero() {
+		return fallback
+	}
+	return v
+}
+
+// Ordinal returns the index of the version in releases that is semantically
+// equal to v. releases must be sorted in ascending order. ok is false if
+// releases has no such version.
+func (v Version) Ordinal(releases []Version) (i int, ok bool) {
+	i, ok = slices.BinarySearchFunc(releases, v, Version.Compare)
+	if !ok {
+		return 0, false
+	}
+	return i, true
+}
+
+// HighestCommon returns the highest version in a that is semantically equal to
+// a version in b. ok is false if a and b have no such version in common.
+func HighestCommon(a, b []Version) (v Version, ok bool) {
+	inB := make(map[Version]bool, len(b))
+	for _, u := range b {
+		u.Format = Any
+		inB[u] = true
+	}
+	for _, u := range a {
+		k := u
+		k.Format = Any
+		if inB[k] && (!ok || u.Compare(v) > 0) {
+			v, ok = u, true
+		}
+	}
+	return v, ok
+}
+
+// HistogramByGeneration returns the number of versions in vs for each
+// Generation.
+func HistogramByGeneration(vs []Version) map[int]int {
+	h := map[int]int{}
+	for _, v := range vs {
+		h[v.Generation]++
+	}
+	return h
+}
+
+// NearestByTime returns the version in m whose time is nearest to target. If
+// several versions are equally near, the semantically lowest is returned. ok
+// is false if m is empty.
+func NearestByTime(m map[Version]time.Time, target time.Time) (v Version, ok bool) {
+	var best time.Duration
+	for u, t := range m {
+		d := t.Sub(target)
+		if d < 0 {
+			d = -d
+		}
+		if !ok || d < best || d == best && u.Less(v) {
+			v, best, ok = u, d, true
+		}
+	}
+	return v, ok
+}
+
+// FindVersion returns the first version in s according to f, along with the
+// offsets of its first byte and the byte after it, like Locate. ok is false if
+// s does not contain a version.
+//
+// Panics if f is not valid format.
+func FindVersion(s string, f Format) (v Version, start, end int, ok bool) {
+	v, start, end, err := Locate([]byte(s), f)
+	return v, start, end, err == nil
+}
+
+// FindAllVersions returns every version in s according to f, in the order
+// they appear. Versions do not overlap; each version is the longest that
+// starts at its position, and the search continues after its end. Returns an
+// empty slice if s contains no versions.
+//
+// Panics if f is not valid format.
+func FindAllVersions(s string, f Format) []Version {
+	return locateAll([]byte(s), f)
+}
+
+// Returns every version in b according to f, in the order they appear. The
+// result is never nil.
+func locateAll(b []byte, f Format) []Version {
+	vs := []Version{}
+	for {
+		v, _, end, err := Locate(b, f)
+		if err != nil {
+			return vs
+		}
+		vs = append(vs, v)
+		b = b[end:]
+	}
+}
+
+// LoadVersions reads the file at name and returns every version found in it
+// according to f, sorted in ascending order. Versions that are semantically
+// equal are included only once, keeping the first to appear. Content that is
+// not a part of a version is ignored.
+//
+// Panics if f is not valid format.
+func LoadVersions(name string, f Format) ([]Version, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return sortUnique(locateAll(b, f)), nil
+}
+
+// Sorts vs in ascending order, then removes versions that are semantically
+// equal to a preceding version. The first of equal versions in vs is kept.
+func sortUnique(vs []Version) []Version {
+	slices.SortStableFunc(vs, Version.Compare)
+	return slices.CompactFunc(vs, Version.Equal)
+}
+
+// Union returns the versions in each of sets, sorted in ascending order.
+// Versions that are semantically equal are included only once, keeping the
+// first to appear.
+func Union(sets ...[]Version) []Version {
+	var vs []Version
+	for _, set := range sets {
+		vs = append(vs, set...)
+	}
+	return sortUnique(vs)
+}
+
+// Locate finds the first version in b according to f, skipping any preceding
+// bytes that are not a part of a version. start and end are the offsets in b
+// of the first byte of the version and the byte after the version. A version
+// may only start at a digit that does not follow another digit. Returns
+// ErrSyntax if b does not contain a version.
+//
+// Panics if f is not valid format.
+func Locate(b []byte, f Format) (v Version, start, end int, err error) {
+	for i := 0; i < len(b); i++ {
+		if b[i] < '0' || '9' < b[i] || i > 0 && '0' <= b[i-1] && b[i-1] <= '9' {
+			continue
+		}
+		if v, n, err := ParseBytes(b[i:], f); err == nil {
+			return v, i, i + n, nil
+		}
+	}
+	return Version{}, 0, 0, ErrSyntax
+}
+
+// Difference returns the versions in a that are not semantically equal to any
+// version in b, sorted in ascending order. Versions in a that are semantically
+// equal to each other are included only once.
+func Difference(a, b []Version) []Version {
+	seen := make(map[Version]bool, len(a)+len(b))
+	for _, v := range b {
+		v.Format = Any
+		seen[v] = true
+	}
+	var diff []Version
+	for _, v := range a {
+		k := v
+		k.Format = Any
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		diff = append(diff, v)
+	}
+	slices.SortFunc(diff, Version.Compare)
+	return diff
+}
+
+// Path returns the components of v separated by slashes, such as
+// "0/123/1/1234567", suitable for a nested directory layout.
+func (v Version) Path() string {
+	return v.FormatSeps([3]string{"/", "/", "/"})
+}
+
+// FromPath parses a version from a path in the form returned by Path. The path
+// is cleaned with path.Clean before parsing. The resulting version has the Dot
+// format. Returns a *SyntaxError with offsets into the cleaned path if p is not
+// a valid version path, or an error wrapping ErrOverflow if a component is too
+// large.
+func FromPath(p string) (Version, error) {
+	p = path.Clean(p)
+	v := Version{Format: Dot}
+	b := []byte(p)
+	for i, comp := range [4]*int{&v.Generation, &v.Version, &v.Patch, &v.Commit} {
+		if i > 0 {
+			if len(b) == 0 || b[0] != '/' {
+				return Version{}, &SyntaxError{Offset: len(p) - len(b), Input: p}
+			}
+			b = b[1:]
+		}
+		if err := parseInt(comp, &b, 0, i); err != nil {
+			return Version{}, syntaxError(err, []byte(p), len(p)-len(b))
+		}
+	}
+	if len(b) > 0 {
+		return Version{}, &SyntaxError{Offset: len(p) - len(b), Input: p}
+	}
+	return v, nil
+}
+
+// ParsePathSegment parses the first slash-separated segment of p that is
+// entirely a version according to f, such as "0.123.1.1234567" in
+// "builds/0.123.1.1234567/RobloxApp.dll". Returns a *SyntaxError at the end of
+// p if no segment is a version.
+//
+// Panics if f is not valid format.
+func ParsePathSegment(p string, f Format) (Version, error) {
+	for _, seg := range strings.Split(p, "/") {
+		if v, err := parseString(seg, f, 0); err == nil {
+			return v, nil
+		}
+	}
+	return Version{}, &SyntaxError{Offset: len(p), Input: p}
+}
+
+// ParseLabeledFields parses a version from whitespace-separated labeled fields,
+// such as "gen:0 ver:123 patch:1 commit:1234567". The labels "gen", "ver",
+// "patch", and "commit" correspond to Generation, Version, Patch, and Commit,
+// respectively. Fields may appear in any order, and missing fields are 0. The
+// resulting version has the Dot format.
+//
+// Returns an error wrapping ErrSyntax if s contains no fields, a field is
+// malformed, or a label is unknown or repeated.
+func ParseLabeledFields(s string) (Version, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return Version{}, ErrSyntax
+	}
+	v := Version{Format: Dot}
+	var seen [4]bool
+	for _, field := range fields {
+		label, value, ok := strings.Cut(field, ":")
+		if !ok {
+			return Version{}, fmt.Errorf("%w: malformed field %q", ErrSyntax, field)
+		}
+		var i int
+		switch label {
+		case "gen":
+			i = 0
+		case "ver":
+			i = 1
+		case "patch":
+			i = 2
+		case "commit":
+			i = 3
+		default:
+			return Version{}, fmt.Errorf("%w: unknown label %q", ErrSyntax, label)
+		}
+		if seen[i] {
+			return Version{}, fmt.Errorf("%w: repeated label %q", ErrSyntax, label)
+		}
+		seen[i] = true
+		comp := [4]*int{&v.Generation, &v.Version, &v.Patch, &v.Commit}[i]
+		b := []byte(value)
+		if parseInt(comp, &b, 0, i) != nil || len(b) > 0 {
+			return Version{}, fmt.Errorf("%w: malformed field %q", ErrSyntax, field)
+		}
+	}
+	return v, nil
+}
+
+// Frozen wraps a Version so that it cannot be modified. The zero Frozen wraps
+// the zero Version.
+type Frozen struct {
+	v Version
+}
+
+// Freeze returns v wrapped in a Frozen.
+func (v Version) Freeze() Frozen {
+	return Frozen{v: v}
+}
+
+// Get returns a copy of the wrapped version.
+func (f Frozen) Get() Version {
+	return f.v
+}
+
+// String returns the wrapped version as a string according to its Format.
+func (f Frozen) String() string {
+	return f.v.String()
+}
+
+// Compare compares the wrapped version to u like Version.Compare.
+func (f Frozen) Compare(u Version) int {
+	return f.v.Compare(u)
+}
+
+// Iterate returns a sequence that yields v, followed by v with Commit increased
+// by step, repeatedly. The sequence ends if Commit would become negative or
+// overflow.
+func (v Version) Iterate(step int) iter.Seq[Version] {
+	return func(yield func(Version) bool) {
+		for {
+			if !yield(v) {
+				return
+			}
+			c := v.Commit + step
+			if c < 0 || step > 0 && c < v.Commit {
+				return
+			}
+			v.Commit = c
+		}
+	}
+}
+
+// Widths of each component in the compact representation.
+var compactWidths = [4]int{2, 3, 4, 7}
+
+// Largest value of each component in the compact representation.
+var compactMax = [4]int{99, 999, 9999, 9999999}
+
+// CompactMax returns the largest value of each component that can be
+// represented by Compact.
+func CompactMax() [4]int {
+	return compactMax
+}
+
+// Compact returns v as a fixed-width string of digits without separators,
+// suitable for a filename. Generation, Version, Patch, and Commit are
+// zero-padded to widths of 2, 3, 4, and 7 digits, respectively, such that
+// 0.123.1.1234567 becomes "0012300011234567". Sorting compact strings lexically
+// sorts the versions semantically. Returns an error wrapping ErrOverflow,
+// naming the first component that is negative or higher than CompactMax.
+// Because such a component cannot be represented without colliding with
+// another version, Compact returns an error rather than only a string.
+func (v Version) Compact() (string, error) {
+	b := make([]byte, 0, 16)
+	for i, c := range v.Components() {
+		if c < 0 || c > compactMax[i] {
+			return "", fmt.Errorf("%w: %s %d does not fit in %d digits", ErrOverflow, componentNames[i], c, compactWidths[i])
+		}
+		d := strconv.Itoa(c)
+		for n := len(d); n < compactWidths[i]; n++ {
+			b = append(b, '0')
+		}
+		b = append(b, d...)
+	}
+	return string(b), nil
+}
+
+// FromCompact parses a version from a string in the form returned by Compact.
+// The resulting version has the Dot format. Returns a *SyntaxError if s is not
+// a valid compact string.
+func FromCompact(s string) (Version, error) {
+	if len(s) != 16 {
+		return Version{}, &SyntaxError{Offset: min(len(s), 16), Input: s}
+	}
+	v := Version{Format: Dot}
+	off := 0
+	for i, comp := range [4]*int{&v.Generation, &v.Version, &v.Patch, &v.Commit} {
+		b := []byte(s[off : off+compactWidths[i]])
+		err := parseInt(comp, &b, 0, i)
+		if err == nil && len(b) > 0 {
+			err = ErrSyntax
+		}
+		if err != nil {
+			return Version{}, syntaxError(err, []byte(s), off+compactWidths[i]-len(b))
+		}
+		off += compactWidths[i]
+	}
+	return v, nil
+}
+
+// SnapshotVersion is a Version with an optional fifth snapshot counter, as
+// appended by some builds. A Snapshot of 0 indicates that the snapshot is not
+// set.
+type SnapshotVersion struct {
+	Version
+	Snapshot int
+}
+
+// CompareWithSnapshot compares v and u like Version.Compare. If the versions
+// are semantically equal, and both have a Snapshot set, then the Snapshots are
+// compared as a tiebreaker.
+func (v SnapshotVersion) CompareWithSnapshot(u SnapshotVersion) int {
+	if c := v.Version.Compare(u.Version); c != 0 {
+		return c
+	}
+	if v.Snapshot == 0 || u.Snapshot == 0 {
+		return 0
+	}
+	switch {
+	case v.Snapshot < u.Snapshot:
+		return -1
+	case v.Snapshot > u.Snapshot:
+		return 1
+	}
+	return 0
+}
+
+// Number of bits of each component in the packed representation.
+var packBits = [4]int{8, 16, 8, 32}
+
+// PackStrict returns v packed into a single integer, with Generation in the
+// highest 8 bits, followed by 16 bits of Version, 8 bits of Patch, and 32 bits
+// of Commit. Packed versions compare in the same order as the versions. Returns
+// an error naming the first component that does not fit in its field.
+func (v Version) PackStrict() (uint64, error) {
+	var p uint64
+	for i, c := range v.Components() {
+		if c < 0 || uint64(c) >= 1<<packBits[i] {
+			return 0, fmt.Errorf("%s %d overflows %d-bit field", componentNames[i], c, packBits[i])
+		}
+		p = p<<packBits[i] | uint64(c)
+	}
+	return p, nil
+}
+
+// Uint64 returns v packed into a single integer like PackStrict. ok is false
+// if a component does not fit in its field.
+func (v Version) Uint64() (p uint64, ok bool) {
+	p, err := v.PackStrict()
+	return p, err == nil
+}
+
+// Timeline returns a position of v on a timeline, such that positions compare
+// in the same order as versions. The position is the packed representation
+// returned by PackStrict. If v cannot be packed, or the position does not fit
+// in an int64, then the position saturates to math.MaxInt64, and ok is false.
+func (v Version) Timeline() (pos int64, ok bool) {
+	p, err := v.PackStrict()
+	if err != nil || p > math.MaxInt64 {
+		return math.MaxInt64, false
+	}
+	return int64(p), true
+}
@@ -1,8 +1,22 @@
 package rbxver
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 // Tests for ParseBytes and Parse.
@@ -84,9 +98,14 @@ func TestParseBytes(t *testing.T) {
 		if n != test.n {
 			t.Errorf("ParseBytes(%q, %s): expected bytes %d, got %d", test.s, fmtstr[test.f], test.n, n)
 		}
-		if err != test.e {
+		if !errors.Is(err, test.e) {
 			t.Errorf("ParseBytes(%q, %s): expected error %v, got %v", test.s, fmtstr[test.f], test.e, err)
 		}
+		if test.e == ErrSyntax {
+			if serr, ok := err.(*SyntaxError); !ok || serr.Offset != n || serr.Input != test.s {
+				t.Errorf("ParseBytes(%q, %s): expected %#v, got %#v", test.s, fmtstr[test.f], &SyntaxError{Offset: n, Input: test.s}, err)
+			}
+		}
 	}
 }
 
@@ -110,3 +129,2610 @@ func TestParse(t *testing.T) {
 		}
 	}
 }
+
+func TestCoerce(t *testing.T) {
+	max := [4]int{1, 999, 99, 9999999}
+	tests := []struct {
+		v, u Version
+	}{
+		{Version{0, 0, 0, 0, Dot}, Version{0, 0, 0, 0, Dot}},
+		{Version{1, 999, 99, 9999999, Dot}, Version{1, 999, 99, 9999999, Dot}},
+		{Version{0, 123, 1, 1234567, Comma}, Version{0, 123, 1, 1234567, Comma}},
+		{Version{2, 1000, 100, 10000000, Dot}, Version{1, 999, 99, 9999999, Dot}},
+		{Version{0, 1000, 1, 10000000, Comma}, Version{0, 999, 1, 9999999, Comma}},
+	}
+	for _, test := range tests {
+		if u := test.v.Coerce(max); u != test.u {
+			t.Errorf("%v.Coerce(%v): expected %v, got %v", test.v, max, test.u, u)
+		}
+	}
+}
+
+// Tests for ParseBytesWith.
+var flagTests = []struct {
+	s     string  // Input string.
+	f     Format  // Input format.
+	flags Flag    // Input flags.
+	v     Version // Expected version.
+	n     int     // Expected read bytes.
+	e     error   // Expected error.
+}{
+	{s: "0.123.1.+1234567", f: Dot, flags: 0, v: Version{0, 123, 1, 0, Any}, n: 8, e: ErrSyntax},
+	{s: "+0.+123.+1.+1234567", f: Dot, flags: 0, v: Version{0, 0, 0, 0, Any}, n: 0, e: ErrSyntax},
+	{s: "0.123.1.+1234567", f: Dot, flags: AllowPlus, v: Version{0, 123, 1, 1234567, Dot}, n: 16, e: nil},
+	{s: "+0.+123.+1.+1234567", f: Any, flags: AllowPlus, v: Version{0, 123, 1, 1234567, Dot}, n: 19, e: nil},
+	{s: "+0, +123, +1, +1234567", f: Comma, flags: AllowPlus, v: Version{0, 123, 1, 1234567, Comma}, n: 22, e: nil},
+	{s: "+", f: Dot, flags: AllowPlus, v: Version{0, 0, 0, 0, Any}, n: 0, e: ErrSyntax},
+	{s: "++0.123.1.1234567", f: Dot, flags: AllowPlus, v: Version{0, 0, 0, 0, Any}, n: 0, e: ErrSyntax},
+	{s: "0.123.+-1.1234567", f: Dot, flags: AllowPlus, v: Version{0, 123, 0, 0, Any}, n: 6, e: ErrSyntax},
+	{s: "0.123,1.1234567", f: Any, flags: 0, v: Version{0, 123, 0, 0, Any}, n: 5, e: ErrSyntax},
+	{s: "0.123, 1.1234567", f: Any, flags: MixedAny, v: Version{0, 123, 1, 1234567, Dot}, n: 16, e: nil},
+	{s: "0, 123.1, 1234567", f: Any, flags: MixedAny, v: Version{0, 123, 1, 1234567, Comma}, n: 17, e: nil},
+	{s: "0.123.1.1234567", f: Any, flags: MixedAny, v: Version{0, 123, 1, 1234567, Dot}, n: 15, e: nil},
+	{s: "0, 123, 1, 1234567", f: Any, flags: MixedAny, v: Version{0, 123, 1, 1234567, Comma}, n: 18, e: nil},
+	{s: "0.123,1.1234567", f: Any, flags: MixedAny, v: Version{0, 123, 1, 1234567, Dot}, n: 15, e: nil},
+	{s: "0,123,1.1234567", f: Any, flags: MixedAny, v: Version{0, 123, 1, 1234567, Comma}, n: 15, e: nil},
+	{s: "0.123,", f: Any, flags: MixedAny, v: Version{0, 123, 0, 0, Any}, n: 5, e: io.ErrUnexpectedEOF},
+	{s: "0.123,1.1234567", f: Any, flags: 0, v: Version{0, 123, 0, 0, Any}, n: 5, e: ErrSyntax},
+	{s: "0.123, 1.1234567", f: Dot, flags: MixedAny, v: Version{0, 123, 0, 0, Any}, n: 5, e: ErrSyntax},
+	{s: "+0.+123, +1.1234567", f: Any, flags: AllowPlus | MixedAny, v: Version{0, 123, 1, 1234567, Dot}, n: 19, e: nil},
+	{s: "0..1.1234567", f: Any, flags: 0, v: Version{0, 0, 0, 0, Any}, n: 2, e: ErrSyntax},
+	{s: ".123.1.1234567", f: Dot, flags: AllowEmpty, v: Version{0, 123, 1, 1234567, Dot}, n: 14, e: nil},
+	{s: "0..1.1234567", f: Any, flags: AllowEmpty, v: Version{0, 0, 1, 1234567, Dot}, n: 12, e: nil},
+	{s: "0.123..1234567", f: Dot, flags: AllowEmpty, v: Version{0, 123, 0, 1234567, Dot}, n: 14, e: nil},
+	{s: ", , , 1234567", f: Any, flags: AllowEmpty, v: Version{0, 0, 0, 1234567, Comma}, n: 13, e: nil},
+	{s: "0, , 1, 1234567", f: Comma, flags: AllowEmpty, v: Version{0, 0, 1, 1234567, Comma}, n: 15, e: nil},
+	{s: "0.123.1..", f: Dot, flags: AllowEmpty, v: Version{0, 123, 1, 0, Any}, n: 8, e: ErrSyntax},
+	{s: "0.123.1.x", f: Dot, flags: AllowEmpty, v: Version{0, 123, 1, 0, Any}, n: 8, e: ErrSyntax},
+	{s: "0..1.1234567", f: Comma, flags: AllowEmpty, v: Version{0, 0, 0, 0, Any}, n: 1, e: ErrSyntax},
+	{s: "\u200b0.123.1.1234567", f: Any, flags: 0, v: Version{0, 0, 0, 0, Any}, n: 0, e: ErrSyntax},
+	{s: "\u200b0.123.1.1234567", f: Any, flags: StripInvisible, v: Version{0, 123, 1, 1234567, Dot}, n: 18, e: nil},
+	{s: "0.\u200b123\u200d.1.\ufeff1234567\u200b", f: Dot, flags: StripInvisible, v: Version{0, 123, 1, 1234567, Dot}, n: 27, e: nil},
+	{s: "0, \u2060123, 1, 1234567", f: Comma, flags: StripInvisible, v: Version{0, 123, 1, 1234567, Comma}, n: 21, e: nil},
+	{s: "0.123.\u200bx.1234567", f: Dot, flags: StripInvisible, v: Version{0, 123, 0, 0, Any}, n: 9, e: ErrSyntax},
+	{s: "0.123.1.1234567\u200bx", f: Dot, flags: StripInvisible, v: Version{0, 123, 1, 1234567, Dot}, n: 18, e: nil},
+	{s: "12.34.56.78 ", f: Dot, flags: 0, v: Version{12, 34, 56, 78, Dot}, n: 11, e: nil},
+	{s: "12.34.56.78 ", f: Dot, flags: Strict, v: Version{12, 34, 56, 78, Any}, n: 11, e: ErrSyntax},
+	{s: " 12.34.56.78", f: Dot, flags: Strict, v: Version{0, 0, 0, 0, Any}, n: 0, e: ErrSyntax},
+	{s: "12.34. 56.78", f: Dot, flags: Strict, v: Version{12, 34, 0, 0, Any}, n: 6, e: ErrSyntax},
+	{s: "12.34.56.78", f: Dot, flags: Strict, v: Version{12, 34, 56, 78, Dot}, n: 11, e: nil},
+	{s: "12, 34, 56, 78", f: Comma, flags: Strict, v: Version{12, 34, 56, 78, Comma}, n: 14, e: nil},
+	{s: "12.34.56.78x", f: Any, flags: Strict, v: Version{12, 34, 56, 78, Any}, n: 11, e: ErrSyntax},
+}
+
+func TestParseBytesConfig(t *testing.T) {
+	tests := []struct {
+		s   string
+		cfg ParseConfig
+		v   Version
+		sep string
+		n   int
+		e   error
+	}{
+		{"0.123.1.1234567", FormatConfig(Any), Version{0, 123, 1, 1234567, Dot}, ".", 15, nil},
+		{"0, 123, 1, 1234567", FormatConfig(Any), Version{0, 123, 1, 1234567, Comma}, ", ", 18, nil},
+		{"0.123.1.1234567", FormatConfig(Dot), Version{0, 123, 1, 1234567, Dot}, ".", 15, nil},
+		{"0, 123, 1, 1234567", FormatConfig(Dot), Version{0, 0, 0, 0, Any}, "", 1, ErrSyntax},
+		{"0, 123, 1, 1234567", FormatConfig(Comma), Version{0, 123, 1, 1234567, Comma}, ", ", 18, nil},
+		{"0-123-1-1234567", ParseConfig{Separator: "-"}, Version{0, 123, 1, 1234567, Any}, "-", 15, nil},
+		{"0_123_1_1234567", ParseConfig{Separator: "_"}, Version{0, 123, 1, 1234567, Any}, "_", 15, nil},
+		{"0_123-1_1234567", ParseConfig{Separator: "_"}, Version{0, 123, 0, 0, Any}, "", 5, ErrSyntax},
+		{"0 - 123 - 1 - 1234567", ParseConfig{Separator: "-"}, Version{0, 0, 0, 0, Any}, "", 1, ErrSyntax},
+		{"0 - 123 -1-  1234567 ", ParseConfig{Separator: "-", AllowSpaces: true}, Version{0, 123, 1, 1234567, Any}, "-", 20, nil},
+		{"0 .  123.1 . 1234567", ParseConfig{AllowSpaces: true}, Version{0, 123, 1, 1234567, Dot}, ".", 20, nil},
+		{"0::123::1::1234567", ParseConfig{Separator: "::"}, Version{0, 123, 1, 1234567, Any}, "::", 18, nil},
+		{"0--1-1234567", ParseConfig{Separator: "-", Flags: AllowEmpty}, Version{0, 0, 1, 1234567, Any}, "-", 12, nil},
+		{"0-123-1-1234567 ", ParseConfig{Separator: "-", Flags: Strict}, Version{0, 123, 1, 1234567, Any}, "", 15, ErrSyntax},
+	}
+	for _, test := range tests {
+		v, sep, n, err := ParseBytesConfig([]byte(test.s), test.cfg)
+		if v != test.v || sep != test.sep || n != test.n || !errors.Is(err, test.e) {
+			t.Errorf("ParseBytesConfig(%q, %+v): expected (%v, %q, %d, %v), got (%v, %q, %d, %v)", test.s, test.cfg, test.v, test.sep, test.n, test.e, v, sep, n, err)
+		}
+	}
+	cfg := FormatConfig(Dot)
+	cfg.Separator = "-"
+	if v := Parse("0.123.1.1234567", Dot); v != (Version{0, 123, 1, 1234567, Dot}) {
+		t.Errorf("Parse after modifying FormatConfig(Dot): expected %v, got %v", Version{0, 123, 1, 1234567, Dot}, v)
+	}
+	for _, cfg := range []ParseConfig{{Separator: "1"}, {Separator: "+"}, {Separator: " ", AllowSpaces: true}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("ParseBytesConfig(%+v): expected panic", cfg)
+				}
+			}()
+			ParseBytesConfig([]byte("0.0.0.0"), cfg)
+		}()
+	}
+}
+
+func TestParseBytesWith(t *testing.T) {
+	for _, test := range flagTests {
+		v, n, err := ParseBytesWith([]byte(test.s), test.f, test.flags)
+		if v != test.v {
+			t.Errorf("ParseBytesWith(%q, %s, %d): expected version %v, got %v", test.s, fmtstr[test.f], test.flags, test.v, v)
+		}
+		if n != test.n {
+			t.Errorf("ParseBytesWith(%q, %s, %d): expected bytes %d, got %d", test.s, fmtstr[test.f], test.flags, test.n, n)
+		}
+		if !errors.Is(err, test.e) {
+			t.Errorf("ParseBytesWith(%q, %s, %d): expected error %v, got %v", test.s, fmtstr[test.f], test.flags, test.e, err)
+		}
+	}
+}
+
+func TestDisplayHint(t *testing.T) {
+	v := Version{0, 123, 1, 1234567, Dot}
+	h := v.DisplayHint()
+	if len(h) != 2 {
+		t.Errorf("%v.DisplayHint(): expected 2 characters, got %q", v, h)
+	}
+	if u := v.DisplayHint(); u != h {
+		t.Errorf("%v.DisplayHint(): expected %q, got %q", v, h, u)
+	}
+	for _, f := range []Format{Any, Comma} {
+		u := v
+		u.Format = f
+		if g := u.DisplayHint(); g != h {
+			t.Errorf("%v.DisplayHint() with format %s: expected %q, got %q", u, fmtstr[f], h, g)
+		}
+	}
+}
+
+func TestParseJSONField(t *testing.T) {
+	obj := map[string]any{}
+	for i := 0; i < 1000; i++ {
+		obj["field"+strconv.Itoa(i)] = map[string]any{
+			"version": "1.2.3.4",
+			"list":    []int{i, i, i},
+		}
+	}
+	obj["version"] = "0.123.1.1234567"
+	obj["number"] = 42
+	data, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := ParseJSONField(data, "version", Any)
+	if err != nil {
+		t.Errorf("ParseJSONField(version): unexpected error %v", err)
+	}
+	if u := (Version{0, 123, 1, 1234567, Dot}); v != u {
+		t.Errorf("ParseJSONField(version): expected %v, got %v", u, v)
+	}
+	if _, err := ParseJSONField(data, "missing", Any); err != ErrNoField {
+		t.Errorf("ParseJSONField(missing): expected error %v, got %v", ErrNoField, err)
+	}
+	var serr *SyntaxError
+	if _, err := ParseJSONField(data, "number", Any); !errors.As(err, &serr) || data[serr.Offset] != '4' {
+		t.Errorf("ParseJSONField(number): expected *SyntaxError at the value, got %v", err)
+	}
+	if _, err := ParseJSONField([]byte(` ["0.123.1.1234567"]`), "version", Any); !errors.As(err, &serr) || serr.Offset != 1 {
+		t.Errorf("ParseJSONField(array): expected *SyntaxError at position 1, got %v", err)
+	}
+}
+
+func TestSameGeneration(t *testing.T) {
+	tests := []struct {
+		v, u       Version
+		gen, minor bool
+	}{
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 123, 1, 1234567, Comma}, true, true},
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 123, 2, 7654321, Dot}, true, true},
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 124, 1, 1234567, Dot}, true, false},
+		{Version{0, 123, 1, 1234567, Dot}, Version{1, 123, 1, 1234567, Dot}, false, false},
+	}
+	for _, test := range tests {
+		if gen := test.v.SameGeneration(test.u); gen != test.gen {
+			t.Errorf("%v.SameGeneration(%v): expected %t, got %t", test.v, test.u, test.gen, gen)
+		}
+		if minor := test.v.SameMinor(test.u); minor != test.minor {
+			t.Errorf("%v.SameMinor(%v): expected %t, got %t", test.v, test.u, test.minor, minor)
+		}
+	}
+}
+
+func TestPool(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		v := AcquireVersion()
+		if *v != (Version{}) {
+			t.Fatalf("AcquireVersion(): expected zero version, got %v", *v)
+		}
+		*v = Parse("0.123.1.1234567", Any)
+		if u := (Version{0, 123, 1, 1234567, Dot}); *v != u {
+			t.Errorf("AcquireVersion(): expected %v, got %v", u, *v)
+		}
+		ReleaseVersion(v)
+		if *v != (Version{}) {
+			t.Errorf("ReleaseVersion(): expected zero version, got %v", *v)
+		}
+	}
+}
+
+func TestPredecessorAt(t *testing.T) {
+	const M = MaxComponent - 1
+	tests := []struct {
+		v     Version
+		level int
+		u     Version
+		ok    bool
+	}{
+		{Version{0, 124, 5, 6, Dot}, 1, Version{}, false},
+		{Version{1, 124, 5, 6, Dot}, 1, Version{0, M, M, M, Dot}, true},
+		{Version{0, 124, 0, 0, Dot}, 2, Version{0, 123, M, M, Dot}, true},
+		{Version{1, 0, 5, 6, Dot}, 2, Version{0, M, M, M, Dot}, true},
+		{Version{0, 0, 5, 6, Dot}, 2, Version{}, false},
+		{Version{0, 124, 5, 6, Comma}, 3, Version{0, 124, 4, M, Comma}, true},
+		{Version{0, 124, 0, 6, Dot}, 3, Version{0, 123, M, M, Dot}, true},
+		{Version{0, 124, 5, 6, Dot}, 4, Version{0, 124, 5, 5, Dot}, true},
+		{Version{0, 124, 5, 0, Dot}, 4, Version{0, 124, 4, M, Dot}, true},
+		{Version{0, 0, 0, 1, Dot}, 4, Version{0, 0, 0, 0, Dot}, true},
+		{Version{0, 0, 0, 0, Dot}, 4, Version{}, false},
+	}
+	for _, test := range tests {
+		u, ok := test.v.PredecessorAt(test.level)
+		if u != test.u || ok != test.ok {
+			t.Errorf("%v.PredecessorAt(%d): expected (%v, %t), got (%v, %t)", test.v, test.level, test.u, test.ok, u, ok)
+		}
+		if ok {
+			if p, err := parseString(u.String(), u.Format, 0); p != u || err != nil {
+				t.Errorf("%v.PredecessorAt(%d): expected result to round-trip, got (%v, %v)", test.v, test.level, p, err)
+			}
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		s      string
+		kind   string
+		reason string
+	}{
+		{"0.123.1.1234567", "version", ""},
+		{"0, 123, 1, 1234567", "version", ""},
+		{"version-0123456789abcdef", "hash", ""},
+		{"version-0123456789ABCDEF", "neither", "expected digit at position 0"},
+		{"", "neither", "unexpected end of string at position 0"},
+		{"0.123.1", "neither", "unexpected end of string at position 7"},
+		{"0123x4", "neither", "expected '.' or ', ' separator at position 4"},
+		{"0.123x4", "neither", "expected '.' separator at position 5"},
+		{"0, 123.1", "neither", "expected ', ' separator at position 6"},
+		{"0.123.a.1", "neither", "expected digit at position 6"},
+		{"0.123.1.1234567x", "neither", "unexpected 'x' at position 15"},
+		{"0.123.1.99999999999999999999", "neither", "number too large at position 8"},
+	}
+	for _, test := range tests {
+		kind, reason := Describe(test.s)
+		if kind != test.kind || reason != test.reason {
+			t.Errorf("Describe(%q): expected (%q, %q), got (%q, %q)", test.s, test.kind, test.reason, kind, reason)
+		}
+	}
+}
+
+func TestMidpoint(t *testing.T) {
+	tests := []struct {
+		a, b Version
+		v    Version
+		e    error
+	}{
+		{Version{0, 123, 1, 100, Dot}, Version{0, 123, 1, 200, Dot}, Version{0, 123, 1, 150, Dot}, nil},
+		{Version{0, 123, 1, 200, Comma}, Version{0, 123, 1, 100, Dot}, Version{0, 123, 1, 150, Comma}, nil},
+		{Version{0, 123, 1, 100, Dot}, Version{0, 123, 1, 101, Dot}, Version{0, 123, 1, 100, Dot}, nil},
+		{Version{0, 123, 1, 100, Dot}, Version{0, 123, 1, 100, Dot}, Version{0, 123, 1, 100, Dot}, nil},
+		{Version{0, 123, 1, 0, Dot}, Version{0, 123, 1, MaxComponent, Dot}, Version{0, 123, 1, MaxComponent / 2, Dot}, nil},
+		{Version{0, 123, 1, 100, Dot}, Version{0, 123, 2, 200, Dot}, Version{}, ErrMismatch},
+		{Version{0, 123, 1, 100, Dot}, Version{0, 124, 1, 200, Dot}, Version{}, ErrMismatch},
+		{Version{0, 123, 1, 100, Dot}, Version{1, 123, 1, 200, Dot}, Version{}, ErrMismatch},
+	}
+	for _, test := range tests {
+		v, err := Midpoint(test.a, test.b)
+		if v != test.v || err != test.e {
+			t.Errorf("Midpoint(%v, %v): expected (%v, %v), got (%v, %v)", test.a, test.b, test.v, test.e, v, err)
+		}
+	}
+}
+
+func TestParseLeadingZeros(t *testing.T) {
+	zeros := strings.Repeat("0", 100000)
+	s := zeros + "1." + zeros + "." + zeros + "3." + zeros
+	v, n, err := ParseBytes([]byte(s), Any)
+	if u := (Version{1, 0, 3, 0, Dot}); v != u {
+		t.Errorf("ParseBytes(leading zeros): expected version %v, got %v", u, v)
+	}
+	if n != len(s) {
+		t.Errorf("ParseBytes(leading zeros): expected bytes %d, got %d", len(s), n)
+	}
+	if err != nil {
+		t.Errorf("ParseBytes(leading zeros): expected error %v, got %v", nil, err)
+	}
+}
+
+func TestIsClean(t *testing.T) {
+	tests := []struct {
+		v     Version
+		clean bool
+	}{
+		{Version{0, 0, 0, 0, Any}, true},
+		{Version{0, 123, 1, 0, Dot}, true},
+		{Version{0, 123, 1, 1, Dot}, false},
+		{Version{0, 123, 1, 1234567, Comma}, false},
+	}
+	for _, test := range tests {
+		if clean := test.v.IsClean(); clean != test.clean {
+			t.Errorf("%v.IsClean(): expected %t, got %t", test.v, test.clean, clean)
+		}
+	}
+}
+
+func TestParseAnyOf(t *testing.T) {
+	tests := []struct {
+		s       string
+		formats []Format
+		v       Version
+		e       error
+	}{
+		{"0.123.1.1234567", []Format{Dot, Comma}, Version{0, 123, 1, 1234567, Dot}, nil},
+		{"0, 123, 1, 1234567", []Format{Dot, Comma}, Version{0, 123, 1, 1234567, Comma}, nil},
+		{"0, 123, 1", []Format{Dot, Comma}, Version{}, io.ErrUnexpectedEOF},
+		{"0.123.1.1234567", []Format{Comma}, Version{}, ErrSyntax},
+		{"0.123.1.1234567x", []Format{Dot}, Version{}, ErrSyntax},
+		{"0.123.1.1234567", nil, Version{}, ErrSyntax},
+	}
+	for _, test := range tests {
+		v, err := ParseAnyOf(test.s, test.formats...)
+		if v != test.v || !errors.Is(err, test.e) {
+			t.Errorf("ParseAnyOf(%q, %v): expected (%v, %v), got (%v, %v)", test.s, test.formats, test.v, test.e, v, err)
+		}
+	}
+}
+
+func TestFormatSeps(t *testing.T) {
+	tests := []struct {
+		v    Version
+		seps [3]string
+		s    string
+	}{
+		{Version{0, 123, 1, 1234567, Dot}, [3]string{".", "-", "."}, "0.123-1.1234567"},
+		{Version{0, 123, 1, 1234567, Comma}, [3]string{"/", "_", "+"}, "0/123_1+1234567"},
+		{Version{0, 123, 1, 1234567, Dot}, [3]string{"", ", ", ""}, "0.123, 1.1234567"},
+		{Version{0, 123, 1, 1234567, Comma}, [3]string{}, "0.123.1.1234567"},
+	}
+	for _, test := range tests {
+		if s := test.v.FormatSeps(test.seps); s != test.s {
+			t.Errorf("%v.FormatSeps(%q): expected %q, got %q", test.v, test.seps, test.s, s)
+		}
+	}
+}
+
+func TestFindGaps(t *testing.T) {
+	contiguous := []Version{
+		{0, 123, 1, 10, Dot},
+		{0, 123, 1, 11, Dot},
+		{0, 123, 1, 12, Dot},
+		{0, 123, 2, 0, Dot},
+		{0, 124, 0, 5, Dot},
+		{1, 0, 0, 0, Dot},
+	}
+	if gaps := FindGaps(contiguous); len(gaps) != 0 {
+		t.Errorf("FindGaps(contiguous): expected no gaps, got %v", gaps)
+	}
+
+	gapped := []Version{
+		{0, 123, 1, 10, Dot},
+		{0, 123, 1, 12, Dot},
+		{0, 123, 1, 13, Dot},
+		{0, 123, 3, 0, Dot},
+		{0, 126, 0, 0, Dot},
+	}
+	expected := [][2]Version{
+		{gapped[0], gapped[1]},
+		{gapped[2], gapped[3]},
+		{gapped[3], gapped[4]},
+	}
+	gaps := FindGaps(gapped)
+	if len(gaps) != len(expected) {
+		t.Fatalf("FindGaps(gapped): expected %v, got %v", expected, gaps)
+	}
+	for i := range gaps {
+		if gaps[i] != expected[i] {
+			t.Errorf("FindGaps(gapped)[%d]: expected %v, got %v", i, expected[i], gaps[i])
+		}
+	}
+}
+
+func TestParseImplicitGen(t *testing.T) {
+	tests := []struct {
+		s string
+		f Format
+		v Version
+		e error
+	}{
+		{"123.1.1234567", Any, Version{0, 123, 1, 1234567, Dot}, nil},
+		{"123.1.1234567", Dot, Version{0, 123, 1, 1234567, Dot}, nil},
+		{"123, 1, 1234567", Any, Version{0, 123, 1, 1234567, Comma}, nil},
+		{"123, 1, 1234567", Comma, Version{0, 123, 1, 1234567, Comma}, nil},
+		{"1.123.1.1234567", Any, Version{1, 123, 1, 1234567, Dot}, nil},
+		{"1, 123, 1, 1234567", Comma, Version{1, 123, 1, 1234567, Comma}, nil},
+		{"123.1.1234567", Comma, Version{}, ErrSyntax},
+		{"123.1", Any, Version{}, io.ErrUnexpectedEOF},
+		{"1.2.3.4.5", Any, Version{}, ErrSyntax},
+	}
+	for _, test := range tests {
+		v, err := ParseImplicitGen(test.s, test.f)
+		if v != test.v || !errors.Is(err, test.e) {
+			t.Errorf("ParseImplicitGen(%q, %s): expected (%v, %v), got (%v, %v)", test.s, fmtstr[test.f], test.v, test.e, v, err)
+		}
+	}
+}
+
+func TestClientSettingsJSON(t *testing.T) {
+	for _, f := range []Format{Any, Dot, Comma} {
+		v := Version{0, 123, 1, 1234567, f}
+		b, err := v.ClientSettingsJSON()
+		if err != nil {
+			t.Errorf("%v.ClientSettingsJSON(): unexpected error %v", v, err)
+		}
+		if s := `{"version":"0.123.1.1234567"}`; string(b) != s {
+			t.Errorf("%v.ClientSettingsJSON(): expected %s, got %s", v, s, b)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		v, u Version
+		r    rune
+	}{
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 123, 1, 1234568, Dot}, '<'},
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 123, 1, 1234567, Comma}, '='},
+		{Version{0, 124, 0, 0, Dot}, Version{0, 123, 1, 1234567, Dot}, '>'},
+	}
+	for _, test := range tests {
+		if r := test.v.Sign(test.u); r != test.r {
+			t.Errorf("%v.Sign(%v): expected %q, got %q", test.v, test.u, test.r, r)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	expected := Version{0, 123, 1, 1234567, Dot}
+	if err := Verify("0.123.1.1234567", expected, Any); err != nil {
+		t.Errorf("Verify(match): unexpected error %v", err)
+	}
+	if err := Verify("0, 123, 1, 1234567", expected, Comma); err != nil {
+		t.Errorf("Verify(match comma): unexpected error %v", err)
+	}
+	err := Verify("0.123.1.1234568", expected, Any)
+	if !errors.Is(err, ErrMismatch) {
+		t.Errorf("Verify(mismatch): expected error %v, got %v", ErrMismatch, err)
+	} else if s := "mismatched components: expected 0.123.1.1234567, got 0.123.1.1234568"; err.Error() != s {
+		t.Errorf("Verify(mismatch): expected message %q, got %q", s, err.Error())
+	}
+	if err := Verify("0.123.1", expected, Any); err != io.ErrUnexpectedEOF {
+		t.Errorf("Verify(invalid): expected error %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+}
+
+func TestChangedComponents(t *testing.T) {
+	tests := []struct {
+		v, u Version
+		m    uint8
+	}{
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 123, 1, 1234567, Comma}, 0b0000},
+		{Version{0, 123, 1, 1234567, Dot}, Version{1, 123, 1, 1234567, Dot}, 0b0001},
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 124, 1, 1234567, Dot}, 0b0010},
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 123, 2, 1234567, Dot}, 0b0100},
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 123, 1, 1234568, Dot}, 0b1000},
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 124, 0, 7654321, Dot}, 0b1110},
+		{Version{0, 123, 1, 1234567, Dot}, Version{1, 0, 0, 0, Dot}, 0b1111},
+	}
+	for _, test := range tests {
+		if m := test.v.ChangedComponents(test.u); m != test.m {
+			t.Errorf("%v.ChangedComponents(%v): expected %04b, got %04b", test.v, test.u, test.m, m)
+		}
+	}
+}
+
+func TestParseShell(t *testing.T) {
+	tests := []struct {
+		s string
+		v Version
+		e error
+	}{
+		{`0.123.1.1234567`, Version{0, 123, 1, 1234567, Dot}, nil},
+		{`'0.123.1.1234567'`, Version{0, 123, 1, 1234567, Dot}, nil},
+		{`"0.123.1.1234567"`, Version{0, 123, 1, 1234567, Dot}, nil},
+		{`"0, 123, 1, 1234567"`, Version{0, 123, 1, 1234567, Comma}, nil},
+		{`'0.123.1.1234567`, Version{}, ErrSyntax},
+		{`0.123.1.1234567"`, Version{}, ErrSyntax},
+		{`'0.123.1.1234567"`, Version{}, ErrSyntax},
+		{`''0.123.1.1234567''`, Version{}, ErrSyntax},
+		{`'`, Version{}, ErrSyntax},
+		{`''`, Version{}, io.ErrUnexpectedEOF},
+	}
+	for _, test := range tests {
+		v, err := ParseShell(test.s, Any)
+		if v != test.v || !errors.Is(err, test.e) {
+			t.Errorf("ParseShell(%q): expected (%v, %v), got (%v, %v)", test.s, test.v, test.e, v, err)
+		}
+	}
+	offsets := []struct {
+		s string
+		n int
+	}{
+		{`'0.123.1.1234567`, 0},
+		{`0.123.1.1234567"`, 15},
+		{`''0.123.1.1234567''`, 1},
+		{`"0.123.x.1234567"`, 7},
+	}
+	for _, test := range offsets {
+		var serr *SyntaxError
+		if _, err := ParseShell(test.s, Any); !errors.As(err, &serr) || serr.Offset != test.n || serr.Input != test.s {
+			t.Errorf("ParseShell(%q): expected *SyntaxError at position %d, got %v", test.s, test.n, err)
+		}
+	}
+}
+
+func TestFormatDefault(t *testing.T) {
+	defer SetOutputFormat(Any)
+	v := Version{0, 123, 1, 1234567, Dot}
+	if s := "0.123.1.1234567"; v.FormatDefault() != s {
+		t.Errorf("%v.FormatDefault(): expected %q, got %q", v, s, v.FormatDefault())
+	}
+	SetOutputFormat(Comma)
+	if s := "0, 123, 1, 1234567"; v.FormatDefault() != s {
+		t.Errorf("%v.FormatDefault() with Comma: expected %q, got %q", v, s, v.FormatDefault())
+	}
+	if s := "0.123.1.1234567"; v.String() != s {
+		t.Errorf("%v.String() with Comma: expected %q, got %q", v, s, v.String())
+	}
+	SetOutputFormat(Dot)
+	v.Format = Comma
+	if s := "0.123.1.1234567"; v.FormatDefault() != s {
+		t.Errorf("%v.FormatDefault() with Dot: expected %q, got %q", v, s, v.FormatDefault())
+	}
+}
+
+func TestLatest(t *testing.T) {
+	for _, v := range []Version{
+		{0, 0, 0, 0, Any},
+		{0, 123, 1, 1234567, Dot},
+		{MaxComponent - 1, MaxComponent - 1, MaxComponent - 1, MaxComponent - 1, Dot},
+		{MaxComponent, MaxComponent, MaxComponent, MaxComponent - 1, Dot},
+	} {
+		if c := v.Compare(Latest); c != -1 {
+			t.Errorf("%v.Compare(Latest): expected -1, got %d", v, c)
+		}
+		if c := Latest.Compare(v); c != 1 {
+			t.Errorf("Latest.Compare(%v): expected 1, got %d", v, c)
+		}
+	}
+	if c := Latest.Compare(Latest); c != 0 {
+		t.Errorf("Latest.Compare(Latest): expected 0, got %d", c)
+	}
+	if v := Parse(Latest.String(), Any); v == Latest {
+		t.Errorf("Parse(%q): unexpectedly produced Latest", Latest.String())
+	}
+}
+
+func TestCommitSpan(t *testing.T) {
+	tests := []struct {
+		v, u Version
+		span int
+		ok   bool
+	}{
+		{Version{0, 123, 1, 100, Dot}, Version{0, 123, 1, 150, Dot}, 50, true},
+		{Version{0, 123, 1, 150, Dot}, Version{0, 123, 1, 100, Comma}, -50, true},
+		{Version{0, 123, 1, 100, Dot}, Version{0, 123, 1, 100, Dot}, 0, true},
+		{Version{0, 123, 1, 100, Dot}, Version{0, 123, 2, 150, Dot}, 0, false},
+		{Version{0, 123, 1, 100, Dot}, Version{0, 124, 1, 150, Dot}, 0, false},
+		{Version{0, 123, 1, 100, Dot}, Version{1, 123, 1, 150, Dot}, 0, false},
+	}
+	for _, test := range tests {
+		span, ok := test.v.CommitSpan(test.u)
+		if span != test.span || ok != test.ok {
+			t.Errorf("%v.CommitSpan(%v): expected (%d, %t), got (%d, %t)", test.v, test.u, test.span, test.ok, span, ok)
+		}
+	}
+}
+
+func TestParseFunc(t *testing.T) {
+	errTooHigh := errors.New("too high")
+	var indexes, values []int
+	v, n, err := ParseFunc([]byte("0.123.1.1234567"), Any, func(index, value int) error {
+		indexes = append(indexes, index)
+		values = append(values, value)
+		if value > 100 {
+			return errTooHigh
+		}
+		return nil
+	})
+	if u := (Version{0, 123, 0, 0, Any}); v != u {
+		t.Errorf("ParseFunc: expected version %v, got %v", u, v)
+	}
+	if n != 5 {
+		t.Errorf("ParseFunc: expected bytes %d, got %d", 5, n)
+	}
+	if err != errTooHigh {
+		t.Errorf("ParseFunc: expected error %v, got %v", errTooHigh, err)
+	}
+	if len(indexes) != 2 || indexes[0] != 0 || indexes[1] != 1 {
+		t.Errorf("ParseFunc: expected indexes [0 1], got %v", indexes)
+	}
+	if len(values) != 2 || values[0] != 0 || values[1] != 123 {
+		t.Errorf("ParseFunc: expected values [0 123], got %v", values)
+	}
+
+	indexes = indexes[:0]
+	v, n, err = ParseFunc([]byte("0.123.1.1234567"), Any, func(index, value int) error {
+		indexes = append(indexes, index)
+		return nil
+	})
+	if u := (Version{0, 123, 1, 1234567, Dot}); v != u || n != 15 || err != nil {
+		t.Errorf("ParseFunc: expected (%v, %d, %v), got (%v, %d, %v)", u, 15, nil, v, n, err)
+	}
+	if len(indexes) != 4 {
+		t.Errorf("ParseFunc: expected 4 calls, got %d", len(indexes))
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	fallback := Version{0, 1, 0, 0, Dot}
+	tests := []struct {
+		v, u Version
+	}{
+		{Version{}, fallback},
+		{Parse("invalid", Any), fallback},
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 123, 1, 1234567, Dot}},
+		{Version{0, 0, 0, 0, Dot}, fallback},
+		{Version{0, 0, 0, 1, Any}, Version{0, 0, 0, 1, Any}},
+	}
+	for _, test := range tests {
+		if u := Coalesce(test.v, fallback); u != test.u {
+			t.Errorf("Coalesce(%v, %v): expected %v, got %v", test.v, fallback, test.u, u)
+		}
+	}
+}
+
+func TestAligned(t *testing.T) {
+	widths := [4]int{1, 3, 2, 7}
+	tests := []struct {
+		v Version
+		s string
+	}{
+		{Version{0, 123, 1, 1234567, Dot}, "0.123. 1.1234567"},
+		{Version{0, 5, 12, 89, Dot}, "0.  5.12.     89"},
+		{Version{0, 5, 12, 89, Comma}, "0,   5, 12,      89"},
+		{Version{10, 1234, 123, 12345678, Dot}, "10.1234.123.12345678"},
+	}
+	for _, test := range tests {
+		if s := test.v.Aligned(widths); s != test.s {
+			t.Errorf("%v.Aligned(%v): expected %q, got %q", test.v, widths, test.s, s)
+		}
+	}
+}
+
+func TestLocate(t *testing.T) {
+	tests := []struct {
+		s          string
+		f          Format
+		v          Version
+		start, end int
+		e          error
+	}{
+		{"0.123.1.1234567", Any, Version{0, 123, 1, 1234567, Dot}, 0, 15, nil},
+		{"Current version: 0.123.1.1234567.", Any, Version{0, 123, 1, 1234567, Dot}, 17, 32, nil},
+		{"Built 12 times, last as 0, 123, 1, 1234567", Any, Version{0, 123, 1, 1234567, Comma}, 24, 42, nil},
+		{"v1.2.3 then v10.20.30.40", Dot, Version{10, 20, 30, 40, Dot}, 13, 24, nil},
+		{"0.123.1.1234567", Comma, Version{}, 0, 0, ErrSyntax},
+		{"no version here", Any, Version{}, 0, 0, ErrSyntax},
+		{"", Any, Version{}, 0, 0, ErrSyntax},
+	}
+	for _, test := range tests {
+		v, start, end, err := Locate([]byte(test.s), test.f)
+		if v != test.v || start != test.start || end != test.end || err != test.e {
+			t.Errorf("Locate(%q, %s): expected (%v, %d, %d, %v), got (%v, %d, %d, %v)", test.s, fmtstr[test.f], test.v, test.start, test.end, test.e, v, start, end, err)
+		}
+		if err == nil && test.s[start:end] != v.String() {
+			t.Errorf("Locate(%q, %s): span %q does not bracket %v", test.s, fmtstr[test.f], test.s[start:end], v)
+		}
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	v := Version{0, 123, 1, 1234567, Dot}
+	tests := []struct {
+		pattern string
+		match   bool
+		e       error
+	}{
+		{"0.123.1.1234567", true, nil},
+		{"0.123.1.*", true, nil},
+		{"0.123.*", true, nil},
+		{"0.123.*.*", true, nil},
+		{"0.*", true, nil},
+		{"*", true, nil},
+		{"0.124.*", false, nil},
+		{"1.*", false, nil},
+		{"0.123.1.7654321", false, nil},
+		{"0.123", false, ErrSyntax},
+		{"0.*.1.*", false, ErrSyntax},
+		{"*.123.*", false, ErrSyntax},
+		{"0.123.1.1234567.*", false, ErrSyntax},
+		{"0.123.x.*", false, ErrSyntax},
+		{"0.-123.*", false, ErrSyntax},
+		{"0.+123.*", false, ErrSyntax},
+		{"0..*", false, ErrSyntax},
+		{"", false, ErrSyntax},
+	}
+	for _, test := range tests {
+		match, err := v.MatchGlob(test.pattern)
+		if match != test.match || err != test.e {
+			t.Errorf("%v.MatchGlob(%q): expected (%t, %v), got (%t, %v)", v, test.pattern, test.match, test.e, match, err)
+		}
+	}
+}
+
+func TestDifference(t *testing.T) {
+	tests := []struct {
+		a, b []Version
+		diff []Version
+	}{
+		{
+			a:    []Version{{0, 3, 0, 0, Dot}, {0, 1, 0, 0, Dot}, {0, 2, 0, 0, Dot}},
+			b:    []Version{{0, 2, 0, 0, Dot}, {0, 4, 0, 0, Dot}},
+			diff: []Version{{0, 1, 0, 0, Dot}, {0, 3, 0, 0, Dot}},
+		},
+		{
+			a:    []Version{{0, 2, 0, 0, Dot}, {0, 1, 0, 0, Comma}},
+			b:    []Version{{0, 3, 0, 0, Dot}},
+			diff: []Version{{0, 1, 0, 0, Comma}, {0, 2, 0, 0, Dot}},
+		},
+		{
+			a:    []Version{{0, 1, 0, 0, Comma}, {0, 2, 0, 0, Dot}, {0, 2, 0, 0, Comma}},
+			b:    []Version{{0, 1, 0, 0, Dot}},
+			diff: []Version{{0, 2, 0, 0, Dot}},
+		},
+		{
+			a:    []Version{{0, 1, 0, 0, Dot}},
+			b:    []Version{{0, 1, 0, 0, Comma}},
+			diff: nil,
+		},
+	}
+	for _, test := range tests {
+		diff := Difference(test.a, test.b)
+		if !slices.Equal(diff, test.diff) {
+			t.Errorf("Difference(%v, %v): expected %v, got %v", test.a, test.b, test.diff, diff)
+		}
+	}
+}
+
+func TestLastGood(t *testing.T) {
+	tests := []struct {
+		v, u Version
+		ok   bool
+	}{
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 123, 1, 1234566, Dot}, true},
+		{Version{0, 123, 1, 1, Comma}, Version{0, 123, 1, 0, Comma}, true},
+		{Version{0, 123, 1, 0, Dot}, Version{0, 123, 1, 0, Dot}, false},
+	}
+	for _, test := range tests {
+		u, ok := test.v.LastGood()
+		if u != test.u || ok != test.ok {
+			t.Errorf("%v.LastGood(): expected (%v, %t), got (%v, %t)", test.v, test.u, test.ok, u, ok)
+		}
+	}
+}
+
+func TestParseToken(t *testing.T) {
+	for _, test := range tests {
+		v, token, n, err := ParseToken([]byte(test.s), test.f)
+		if v != test.v || n != test.n || !errors.Is(err, test.e) {
+			t.Errorf("ParseToken(%q, %s): expected (%v, %d, %v), got (%v, %d, %v)", test.s, fmtstr[test.f], test.v, test.n, test.e, v, n, err)
+		}
+		if token != test.s[:test.n] {
+			t.Errorf("ParseToken(%q, %s): expected token %q, got %q", test.s, fmtstr[test.f], test.s[:test.n], token)
+		}
+	}
+}
+
+func TestCompilePattern(t *testing.T) {
+	re, err := CompilePattern("0.123.*")
+	if err != nil {
+		t.Fatalf("CompilePattern: unexpected error %v", err)
+	}
+	tests := []struct {
+		v     Version
+		match bool
+	}{
+		{Version{0, 123, 1, 1234567, Dot}, true},
+		{Version{0, 123, 1, 1234567, Comma}, true},
+		{Version{0, 123, 0, 0, Any}, true},
+		{Version{0, 124, 1, 1234567, Dot}, false},
+		{Version{0, 1234, 1, 1234567, Dot}, false},
+		{Version{10, 123, 1, 1234567, Dot}, false},
+	}
+	for _, test := range tests {
+		if match := test.v.MatchesPattern(re); match != test.match {
+			t.Errorf("%v.MatchesPattern(%s): expected %t, got %t", test.v, re, test.match, match)
+		}
+		if match, _ := test.v.MatchGlob("0.123.*"); match != test.match {
+			t.Errorf("%v.MatchGlob(%q): expected %t, got %t", test.v, "0.123.*", test.match, match)
+		}
+	}
+	if _, err := CompilePattern("0.*.1.*"); err != ErrSyntax {
+		t.Errorf("CompilePattern(invalid): expected error %v, got %v", ErrSyntax, err)
+	}
+}
+
+func TestParseTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		level int
+		v     Version
+		e     error
+	}{
+		{"0.123.1.1234567", 2, Version{0, 123, 0, 0, Dot}, nil},
+		{"0, 123, 1, 1234567", 2, Version{0, 123, 0, 0, Comma}, nil},
+		{"0.123.1.1234567", 1, Version{0, 0, 0, 0, Dot}, nil},
+		{"0.123.1.1234567", 3, Version{0, 123, 1, 0, Dot}, nil},
+		{"0.123.1.1234567", 4, Version{0, 123, 1, 1234567, Dot}, nil},
+		{"0.123", 2, Version{}, io.ErrUnexpectedEOF},
+	}
+	for _, test := range tests {
+		v, err := ParseTruncate(test.s, Any, test.level)
+		if v != test.v || err != test.e {
+			t.Errorf("ParseTruncate(%q, %d): expected (%v, %v), got (%v, %v)", test.s, test.level, test.v, test.e, v, err)
+		}
+	}
+}
+
+func TestPath(t *testing.T) {
+	for _, v := range []Version{
+		{0, 0, 0, 0, Dot},
+		{0, 123, 1, 1234567, Dot},
+		{1, 2, 3, 4, Dot},
+	} {
+		p := v.Path()
+		u, err := FromPath(p)
+		if u != v || err != nil {
+			t.Errorf("FromPath(%q): expected (%v, %v), got (%v, %v)", p, v, nil, u, err)
+		}
+	}
+	if p := (Version{0, 123, 1, 1234567, Comma}).Path(); p != "0/123/1/1234567" {
+		t.Errorf("Path(): expected %q, got %q", "0/123/1/1234567", p)
+	}
+	if v, err := FromPath("0/123//1/./1234567/"); v != (Version{0, 123, 1, 1234567, Dot}) || err != nil {
+		t.Errorf("FromPath(unclean): expected (%v, %v), got (%v, %v)", Version{0, 123, 1, 1234567, Dot}, nil, v, err)
+	}
+	for _, test := range []struct {
+		p string
+		n int
+	}{
+		{"", 0},
+		{"0/123/1", 7},
+		{"0/123/1/1234567/8", 15},
+		{"/0/123/1/1234567", 0},
+		{"0/123/x/1234567", 6},
+		{"0/123/1x/1234567", 7},
+	} {
+		var serr *SyntaxError
+		if _, err := FromPath(test.p); !errors.As(err, &serr) || serr.Offset != test.n {
+			t.Errorf("FromPath(%q): expected *SyntaxError at position %d, got %v", test.p, test.n, err)
+		}
+	}
+	if _, err := FromPath("0/123/1/99999999999999999999"); !errors.Is(err, ErrOverflow) {
+		t.Errorf("FromPath(overflow): expected error %v, got %v", ErrOverflow, err)
+	}
+}
+
+func TestParsePathSegment(t *testing.T) {
+	tests := []struct {
+		p string
+		f Format
+		v Version
+	}{
+		{"0.123.1.1234567", Any, Version{0, 123, 1, 1234567, Dot}},
+		{"0.123.1.1234567/RobloxApp.dll", Any, Version{0, 123, 1, 1234567, Dot}},
+		{"builds/0.123.1.1234567/RobloxApp.dll", Any, Version{0, 123, 1, 1234567, Dot}},
+		{"builds/windows/0.123.1.1234567", Dot, Version{0, 123, 1, 1234567, Dot}},
+		{"builds/0, 123, 1, 1234567/RobloxApp.dll", Comma, Version{0, 123, 1, 1234567, Comma}},
+		{"builds/0.123.1.1234567x/0.124.0.1/RobloxApp.dll", Any, Version{0, 124, 0, 1, Dot}},
+		{"/builds/0.123.1.1234567/0.124.0.1/", Any, Version{0, 123, 1, 1234567, Dot}},
+	}
+	for _, test := range tests {
+		if v, err := ParsePathSegment(test.p, test.f); v != test.v || err != nil {
+			t.Errorf("ParsePathSegment(%q, %s): expected (%v, %v), got (%v, %v)", test.p, fmtstr[test.f], test.v, nil, v, err)
+		}
+	}
+	for _, p := range []string{"", "/", "builds/RobloxApp.dll", "builds/0.123.1/RobloxApp.dll", "builds/0, 123, 1, 1234567/RobloxApp.dll"} {
+		var serr *SyntaxError
+		if _, err := ParsePathSegment(p, Dot); !errors.As(err, &serr) || serr.Offset != len(p) || serr.Input != p {
+			t.Errorf("ParsePathSegment(%q, Dot): expected *SyntaxError at position %d, got %v", p, len(p), err)
+		}
+	}
+}
+
+func TestParseLabeledFields(t *testing.T) {
+	tests := []struct {
+		s string
+		v Version
+		e bool
+	}{
+		{"gen:0 ver:123 patch:1 commit:1234567", Version{0, 123, 1, 1234567, Dot}, false},
+		{"commit:1234567 patch:1 ver:123 gen:0", Version{0, 123, 1, 1234567, Dot}, false},
+		{"  ver:123\tcommit:1234567 ", Version{0, 123, 0, 1234567, Dot}, false},
+		{"gen:1", Version{1, 0, 0, 0, Dot}, false},
+		{"", Version{}, true},
+		{"gen:0 build:5", Version{}, true},
+		{"gen:0 gen:1", Version{}, true},
+		{"gen:0 ver", Version{}, true},
+		{"gen:0 ver:x", Version{}, true},
+		{"gen:0 ver:-1", Version{}, true},
+	}
+	for _, test := range tests {
+		v, err := ParseLabeledFields(test.s)
+		if v != test.v {
+			t.Errorf("ParseLabeledFields(%q): expected version %v, got %v", test.s, test.v, v)
+		}
+		if test.e && !errors.Is(err, ErrSyntax) {
+			t.Errorf("ParseLabeledFields(%q): expected error %v, got %v", test.s, ErrSyntax, err)
+		} else if !test.e && err != nil {
+			t.Errorf("ParseLabeledFields(%q): unexpected error %v", test.s, err)
+		}
+	}
+}
+
+func TestHighestCommon(t *testing.T) {
+	tests := []struct {
+		a, b []Version
+		v    Version
+		ok   bool
+	}{
+		{
+			a:  []Version{{0, 1, 0, 0, Dot}, {0, 3, 0, 0, Dot}, {0, 2, 0, 0, Dot}},
+			b:  []Version{{0, 2, 0, 0, Dot}, {0, 1, 0, 0, Dot}, {0, 4, 0, 0, Dot}},
+			v:  Version{0, 2, 0, 0, Dot},
+			ok: true,
+		},
+		{
+			a:  []Version{{0, 1, 0, 0, Comma}, {0, 2, 0, 0, Comma}},
+			b:  []Version{{0, 2, 0, 0, Dot}},
+			v:  Version{0, 2, 0, 0, Comma},
+			ok: true,
+		},
+		{
+			a:  []Version{{0, 1, 0, 0, Dot}, {0, 3, 0, 0, Dot}},
+			b:  []Version{{0, 2, 0, 0, Dot}, {0, 4, 0, 0, Dot}},
+			v:  Version{},
+			ok: false,
+		},
+		{
+			a:  nil,
+			b:  []Version{{0, 2, 0, 0, Dot}},
+			v:  Version{},
+			ok: false,
+		},
+	}
+	for _, test := range tests {
+		v, ok := HighestCommon(test.a, test.b)
+		if v != test.v || ok != test.ok {
+			t.Errorf("HighestCommon(%v, %v): expected (%v, %t), got (%v, %t)", test.a, test.b, test.v, test.ok, v, ok)
+		}
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	v := Version{0, 123, 1, 1234567, Dot}
+	f := v.Freeze()
+	v.Commit = 0
+	u := f.Get()
+	u.Format = Comma
+	if g := f.Get(); g != (Version{0, 123, 1, 1234567, Dot}) {
+		t.Errorf("Frozen.Get(): expected %v, got %v", Version{0, 123, 1, 1234567, Dot}, g)
+	}
+	if s := f.String(); s != "0.123.1.1234567" {
+		t.Errorf("Frozen.String(): expected %q, got %q", "0.123.1.1234567", s)
+	}
+	if c := f.Compare(v); c != 1 {
+		t.Errorf("Frozen.Compare(%v): expected 1, got %d", v, c)
+	}
+	if c := f.Compare(u); c != 0 {
+		t.Errorf("Frozen.Compare(%v): expected 0, got %d", u, c)
+	}
+}
+
+func TestNormalizeBatch(t *testing.T) {
+	ss := []string{"0.123.1.1234567", "0, 124, 2, 7654321", "invalid", "0.125.0"}
+	expected := []string{"0, 123, 1, 1234567", "0, 124, 2, 7654321", "", ""}
+	expectedErrs := []error{nil, nil, ErrSyntax, io.ErrUnexpectedEOF}
+	results, errs := NormalizeBatch(ss, Comma)
+	if !slices.Equal(results, expected) {
+		t.Errorf("NormalizeBatch(%q): expected %q, got %q", ss, expected, results)
+	}
+	for i, err := range errs {
+		if !errors.Is(err, expectedErrs[i]) {
+			t.Errorf("NormalizeBatch(%q): expected error %v at %d, got %v", ss, expectedErrs[i], i, err)
+		}
+	}
+}
+
+func TestNormalizedVector(t *testing.T) {
+	v := Version{1, 250, 3, 500, Dot}
+	if vec := v.FeatureVector(); vec != [4]float64{1, 250, 3, 500} {
+		t.Errorf("%v.FeatureVector(): expected %v, got %v", v, [4]float64{1, 250, 3, 500}, vec)
+	}
+	tests := []struct {
+		max [4]int
+		vec [4]float64
+	}{
+		{[4]int{2, 1000, 4, 1000}, [4]float64{0.5, 0.25, 0.75, 0.5}},
+		{[4]int{1, 250, 3, 500}, [4]float64{1, 1, 1, 1}},
+		{[4]int{1, 100, 1, 100}, [4]float64{1, 1, 1, 1}},
+		{[4]int{0, -1, 6, 2000}, [4]float64{0, 0, 0.5, 0.25}},
+	}
+	for _, test := range tests {
+		if vec := v.NormalizedVector(test.max); vec != test.vec {
+			t.Errorf("%v.NormalizedVector(%v): expected %v, got %v", v, test.max, test.vec, vec)
+		}
+	}
+}
+
+func TestIterate(t *testing.T) {
+	v := Version{0, 123, 1, 100, Dot}
+	var vs []Version
+	for u := range v.Iterate(5) {
+		vs = append(vs, u)
+		if len(vs) == 4 {
+			break
+		}
+	}
+	expected := []Version{
+		{0, 123, 1, 100, Dot},
+		{0, 123, 1, 105, Dot},
+		{0, 123, 1, 110, Dot},
+		{0, 123, 1, 115, Dot},
+	}
+	if !slices.Equal(vs, expected) {
+		t.Errorf("%v.Iterate(5): expected %v, got %v", v, expected, vs)
+	}
+
+	vs = slices.Collect(Version{0, 123, 1, 5, Dot}.Iterate(-2))
+	expected = []Version{
+		{0, 123, 1, 5, Dot},
+		{0, 123, 1, 3, Dot},
+		{0, 123, 1, 1, Dot},
+	}
+	if !slices.Equal(vs, expected) {
+		t.Errorf("Iterate(-2): expected %v, got %v", expected, vs)
+	}
+
+	vs = slices.Collect(Version{0, 123, 1, MaxComponent - 1, Dot}.Iterate(1))
+	if len(vs) != 2 {
+		t.Errorf("Iterate(1) near MaxComponent: expected 2 versions, got %d", len(vs))
+	}
+}
+
+func TestLoadVersions(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "versions.txt")
+	content := `# Deployed versions
+0.124.0.7654321
+junk line
+0.123.1.1234567
+0, 123, 1, 1234567 (duplicate)
+version-0123456789abcdef 0.122.0.1
+0.124.0.7654321
+`
+	if err := os.WriteFile(name, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+	vs, err := LoadVersions(name, Any)
+	if err != nil {
+		t.Fatalf("LoadVersions: unexpected error %v", err)
+	}
+	expected := []Version{
+		{0, 122, 0, 1, Dot},
+		{0, 123, 1, 1234567, Dot},
+		{0, 124, 0, 7654321, Dot},
+	}
+	if !slices.Equal(vs, expected) {
+		t.Errorf("LoadVersions: expected %v, got %v", expected, vs)
+	}
+	if _, err := LoadVersions(filepath.Join(t.TempDir(), "missing.txt"), Any); err == nil {
+		t.Errorf("LoadVersions(missing): expected error")
+	}
+}
+
+func TestStrictlyBetween(t *testing.T) {
+	lo := Version{0, 123, 0, 0, Dot}
+	hi := Version{0, 124, 0, 0, Dot}
+	tests := []struct {
+		v, lo, hi Version
+		between   bool
+	}{
+		{Version{0, 123, 1, 1234567, Dot}, lo, hi, true},
+		{Version{0, 123, 0, 1, Dot}, lo, hi, true},
+		{Version{0, 123, 0, 0, Comma}, lo, hi, false},
+		{Version{0, 124, 0, 0, Dot}, lo, hi, false},
+		{Version{0, 122, 9, 9, Dot}, lo, hi, false},
+		{Version{0, 124, 0, 1, Dot}, lo, hi, false},
+		{Version{0, 123, 1, 1234567, Dot}, hi, lo, false},
+		{Version{0, 123, 0, 0, Dot}, lo, lo, false},
+	}
+	for _, test := range tests {
+		if between := test.v.StrictlyBetween(test.lo, test.hi); between != test.between {
+			t.Errorf("%v.StrictlyBetween(%v, %v): expected %t, got %t", test.v, test.lo, test.hi, test.between, between)
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	limits := CompactMax()
+	limits[0] = 0
+	if m := CompactMax(); m != [4]int{99, 999, 9999, 9999999} {
+		t.Errorf("CompactMax(): expected %v, got %v", [4]int{99, 999, 9999, 9999999}, m)
+	}
+	if s, err := (Version{0, 123, 1, 1234567, Comma}).Compact(); s != "0012300011234567" || err != nil {
+		t.Errorf("Compact(): expected (%q, %v), got (%q, %v)", "0012300011234567", nil, s, err)
+	}
+	for _, v := range []Version{
+		{100, 0, 0, 0, Dot},
+		{0, 1000, 1, 1, Dot},
+		{0, 999, 10000, 1, Dot},
+		{0, 999, 1, 10000000, Dot},
+		{0, -1, 1, 1, Dot},
+	} {
+		if s, err := v.Compact(); s != "" || !errors.Is(err, ErrOverflow) {
+			t.Errorf("%v.Compact(): expected error %v, got (%q, %v)", v, ErrOverflow, s, err)
+		}
+	}
+	vs := []Version{
+		{0, 0, 0, 0, Dot},
+		{0, 9, 0, 0, Dot},
+		{0, 10, 0, 5, Dot},
+		{0, 123, 1, 1234567, Dot},
+		{0, 123, 12, 0, Dot},
+		{1, 0, 0, 1, Dot},
+		{99, 999, 9999, 9999999, Dot},
+	}
+	var compact []string
+	for _, v := range vs {
+		s, err := v.Compact()
+		if err != nil {
+			t.Errorf("%v.Compact(): unexpected error %v", v, err)
+		}
+		compact = append(compact, s)
+		if u, err := FromCompact(s); u != v || err != nil {
+			t.Errorf("FromCompact(%q): expected (%v, %v), got (%v, %v)", s, v, nil, u, err)
+		}
+	}
+	if !slices.IsSorted(compact) {
+		t.Errorf("Compact(): expected lexical order to match semantic order, got %q", compact)
+	}
+	for _, test := range []struct {
+		s string
+		n int
+	}{
+		{"", 0},
+		{"001230001123456", 15},
+		{"00123000112345678", 16},
+		{"0012300011234x67", 13},
+		{"00123+0011234567", 5},
+	} {
+		var serr *SyntaxError
+		if _, err := FromCompact(test.s); !errors.As(err, &serr) || serr.Offset != test.n {
+			t.Errorf("FromCompact(%q): expected *SyntaxError at position %d, got %v", test.s, test.n, err)
+		}
+	}
+}
+
+func TestCompareWithSnapshot(t *testing.T) {
+	v := Version{0, 123, 1, 1234567, Dot}
+	u := Version{0, 123, 1, 1234568, Dot}
+	tests := []struct {
+		a, b SnapshotVersion
+		c    int
+	}{
+		{SnapshotVersion{v, 1}, SnapshotVersion{v, 2}, -1},
+		{SnapshotVersion{v, 3}, SnapshotVersion{v, 2}, 1},
+		{SnapshotVersion{v, 2}, SnapshotVersion{v, 2}, 0},
+		{SnapshotVersion{v, 0}, SnapshotVersion{v, 2}, 0},
+		{SnapshotVersion{v, 2}, SnapshotVersion{v, 0}, 0},
+		{SnapshotVersion{v, 9}, SnapshotVersion{u, 1}, -1},
+		{SnapshotVersion{u, 1}, SnapshotVersion{v, 9}, 1},
+	}
+	for _, test := range tests {
+		if c := test.a.CompareWithSnapshot(test.b); c != test.c {
+			t.Errorf("%v.CompareWithSnapshot(%v): expected %d, got %d", test.a, test.b, test.c, c)
+		}
+		if c := test.a.Compare(test.b.Version); c != test.a.Version.Compare(test.b.Version) {
+			t.Errorf("%v.Compare(%v): snapshot unexpectedly affected result", test.a, test.b.Version)
+		}
+	}
+}
+
+func TestLogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("deployed", "build", Version{0, 123, 1, 1234567, Dot})
+	s := `{"level":"INFO","msg":"deployed","build":{"generation":0,"version":123,"patch":1,"commit":1234567}}` + "\n"
+	if buf.String() != s {
+		t.Errorf("LogValue: expected %s, got %s", s, buf.String())
+	}
+}
+
+func TestEqualIgnoring(t *testing.T) {
+	v := Version{0, 123, 1, 1234567, Dot}
+	tests := []struct {
+		u     Version
+		equal [4]bool
+	}{
+		{Version{0, 123, 1, 1234567, Comma}, [4]bool{true, true, true, true}},
+		{Version{1, 123, 1, 1234567, Dot}, [4]bool{true, false, false, false}},
+		{Version{0, 124, 1, 1234567, Dot}, [4]bool{false, true, false, false}},
+		{Version{0, 123, 2, 1234567, Dot}, [4]bool{false, false, true, false}},
+		{Version{0, 123, 1, 7654321, Dot}, [4]bool{false, false, false, true}},
+		{Version{0, 123, 2, 7654321, Dot}, [4]bool{false, false, false, false}},
+	}
+	for _, test := range tests {
+		for i, equal := range test.equal {
+			if e := v.EqualIgnoring(test.u, i); e != equal {
+				t.Errorf("%v.EqualIgnoring(%v, %d): expected %t, got %t", v, test.u, i, equal, e)
+			}
+		}
+	}
+	for _, i := range []int{-1, 4} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("EqualIgnoring(%d): expected panic", i)
+				}
+			}()
+			v.EqualIgnoring(v, i)
+		}()
+	}
+}
+
+func TestHistogramByGeneration(t *testing.T) {
+	vs := []Version{
+		{0, 123, 1, 1234567, Dot},
+		{0, 124, 0, 7654321, Comma},
+		{1, 0, 0, 0, Dot},
+		{0, 1, 0, 0, Dot},
+		{2, 5, 0, 0, Dot},
+		{1, 3, 0, 0, Dot},
+	}
+	expected := map[int]int{0: 3, 1: 2, 2: 1}
+	h := HistogramByGeneration(vs)
+	if len(h) != len(expected) {
+		t.Errorf("HistogramByGeneration: expected %v, got %v", expected, h)
+	}
+	for g, n := range expected {
+		if h[g] != n {
+			t.Errorf("HistogramByGeneration[%d]: expected %d, got %d", g, n, h[g])
+		}
+	}
+	if h := HistogramByGeneration(nil); len(h) != 0 {
+		t.Errorf("HistogramByGeneration(nil): expected empty, got %v", h)
+	}
+}
+
+func TestText(t *testing.T) {
+	for _, v := range []Version{
+		{0, 123, 1, 1234567, Dot},
+		{0, 123, 1, 1234567, Comma},
+	} {
+		b, err := v.MarshalText()
+		if err != nil {
+			t.Errorf("%v.MarshalText(): unexpected error %v", v, err)
+		}
+		if string(b) != v.String() {
+			t.Errorf("%v.MarshalText(): expected %q, got %q", v, v.String(), b)
+		}
+		var u Version
+		if err := u.UnmarshalText(b); err != nil {
+			t.Errorf("UnmarshalText(%q): unexpected error %v", b, err)
+		}
+		if u != v {
+			t.Errorf("UnmarshalText(%q): expected %v, got %v", b, v, u)
+		}
+	}
+
+	u := Version{1, 2, 3, 4, Dot}
+	if err := u.UnmarshalText([]byte("0.123.1.1234567x")); !errors.Is(err, ErrSyntax) {
+		t.Errorf("UnmarshalText(trailing): expected error %v, got %v", ErrSyntax, err)
+	}
+	var serr *SyntaxError
+	if err := u.UnmarshalText([]byte("0.123.1")); !errors.As(err, &serr) || serr.Offset != 7 {
+		t.Errorf("UnmarshalText(short): expected error at position 7, got %v", err)
+	}
+	if u != (Version{1, 2, 3, 4, Dot}) {
+		t.Errorf("UnmarshalText(invalid): version unexpectedly modified to %v", u)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	v := Version{0, 123, 1, 1234567, Comma}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Errorf("MarshalJSON: unexpected error %v", err)
+	}
+	if s := `"0, 123, 1, 1234567"`; string(b) != s {
+		t.Errorf("MarshalJSON: expected %s, got %s", s, b)
+	}
+	var u Version
+	if err := json.Unmarshal(b, &u); err != nil || u != v {
+		t.Errorf("UnmarshalJSON(%s): expected (%v, %v), got (%v, %v)", b, v, nil, u, err)
+	}
+
+	b, err = json.Marshal(VersionArray(v))
+	if err != nil {
+		t.Errorf("MarshalJSON(array): unexpected error %v", err)
+	}
+	if s := `[0,123,1,1234567]`; string(b) != s {
+		t.Errorf("MarshalJSON(array): expected %s, got %s", s, b)
+	}
+	u = Version{}
+	if err := json.Unmarshal(b, &u); err != nil || u != (Version{0, 123, 1, 1234567, Dot}) {
+		t.Errorf("UnmarshalJSON(%s): expected (%v, %v), got (%v, %v)", b, Version{0, 123, 1, 1234567, Dot}, nil, u, err)
+	}
+	var a VersionArray
+	if err := json.Unmarshal(b, &a); err != nil || a != (VersionArray{0, 123, 1, 1234567, Dot}) {
+		t.Errorf("VersionArray.UnmarshalJSON(%s): expected (%v, %v), got (%v, %v)", b, VersionArray{0, 123, 1, 1234567, Dot}, nil, a, err)
+	}
+	if b, err := json.Marshal(struct {
+		V Version
+		A VersionArray
+	}{v, VersionArray(v)}); err != nil || string(b) != `{"V":"0, 123, 1, 1234567","A":[0,123,1,1234567]}` {
+		t.Errorf("MarshalJSON(mixed): expected (%s, %v), got (%s, %v)", `{"V":"0, 123, 1, 1234567","A":[0,123,1,1234567]}`, nil, b, err)
+	}
+
+	// Strings are parsed according to Any, regardless of the current Format.
+	u = Version{Format: Dot}
+	if err := json.Unmarshal([]byte(`"0, 1, 2, 3"`), &u); err != nil || u != (Version{0, 1, 2, 3, Comma}) {
+		t.Errorf("UnmarshalJSON(comma into Dot): expected (%v, %v), got (%v, %v)", Version{0, 1, 2, 3, Comma}, nil, u, err)
+	}
+	if err := json.Unmarshal([]byte(`"0.1.2.99999999999999999999"`), &u); !errors.Is(err, ErrOverflow) {
+		t.Errorf("UnmarshalJSON(overflow): expected error %v, got %v", ErrOverflow, err)
+	}
+
+	for _, s := range []string{
+		`"0.123.1"`,
+		`"0.123.1.1234567x"`,
+		`[0,123,1]`,
+		`[0,123,1,1234567,8]`,
+		`[0,-123,1,1234567]`,
+	} {
+		if err := json.Unmarshal([]byte(s), &u); !errors.Is(err, ErrSyntax) {
+			t.Errorf("UnmarshalJSON(%s): expected error %v, got %v", s, ErrSyntax, err)
+		}
+	}
+	var serr *SyntaxError
+	if err := json.Unmarshal([]byte(`"0.1.x.3"`), &u); !errors.As(err, &serr) || serr.Offset != 4 || serr.Input != "0.1.x.3" {
+		t.Errorf("UnmarshalJSON: expected error at position 4 with input, got %v", err)
+	}
+	if err := json.Unmarshal([]byte(`"0.1.2"`), &u); err == nil || !strings.Contains(err.Error(), `"0.1.2"`) {
+		t.Errorf("UnmarshalJSON: expected error containing input, got %v", err)
+	}
+}
+
+func TestDetectStyle(t *testing.T) {
+	tests := []struct {
+		s     string
+		style Style
+	}{
+		{"0.123.1.1234567", StyleDot},
+		{"0, 123, 1, 1234567", StyleComma},
+		{"version-0123456789abcdef", StyleHash},
+		{"", StyleNone},
+		{"hello", StyleNone},
+		{"0.123.1", StyleNone},
+		{"0.123, 1.1234567", StyleNone},
+		{"0,123,1,1234567", StyleNone},
+		{".0.123.1", StyleNone},
+		{"0.123.1.1234567x", StyleNone},
+		{"version-0123", StyleNone},
+	}
+	for _, test := range tests {
+		if style := DetectStyle(test.s); style != test.style {
+			t.Errorf("DetectStyle(%q): expected %d, got %d", test.s, test.style, style)
+		}
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	releases := []Version{
+		{0, 121, 0, 100, Dot},
+		{0, 122, 0, 200, Dot},
+		{0, 123, 1, 1234567, Dot},
+		{0, 124, 0, 400, Dot},
+	}
+	tests := []struct {
+		v  Version
+		i  int
+		ok bool
+	}{
+		{Version{0, 121, 0, 100, Dot}, 0, true},
+		{Version{0, 123, 1, 1234567, Comma}, 2, true},
+		{Version{0, 124, 0, 400, Dot}, 3, true},
+		{Version{0, 123, 1, 1234568, Dot}, 0, false},
+		{Version{0, 120, 0, 0, Dot}, 0, false},
+		{Version{0, 125, 0, 0, Dot}, 0, false},
+	}
+	for _, test := range tests {
+		i, ok := test.v.Ordinal(releases)
+		if i != test.i || ok != test.ok {
+			t.Errorf("%v.Ordinal: expected (%d, %t), got (%d, %t)", test.v, test.i, test.ok, i, ok)
+		}
+	}
+}
+
+func TestBinary(t *testing.T) {
+	v := Version{0, 123, 1, 1234567, Comma}
+	b, err := v.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%v.MarshalBinary(): unexpected error %v", v, err)
+	}
+	expected := []byte{0, 0, 0, 0, 0, 0, 0, 123, 0, 0, 0, 1, 0, 0x12, 0xD6, 0x87, 2}
+	if !bytes.Equal(b, expected) {
+		t.Errorf("%v.MarshalBinary(): expected %v, got %v", v, expected, b)
+	}
+	var u Version
+	if err := u.UnmarshalBinary(b); err != nil || u != v {
+		t.Errorf("UnmarshalBinary(%v): expected (%v, %v), got (%v, %v)", b, v, nil, u, err)
+	}
+
+	if _, err := (Version{0, 1 << 31, 0, 0, Dot}).MarshalBinary(); err == nil {
+		t.Errorf("MarshalBinary(overflow): expected error")
+	}
+	for _, b := range [][]byte{
+		nil,
+		expected[:16],
+		append(slices.Clone(expected), 0),
+		{0, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 1, 0, 0x12, 0xD6, 0x87, 2},
+		{0, 0, 0, 0, 0, 0, 0, 123, 0, 0, 0, 1, 0, 0x12, 0xD6, 0x87, 3},
+	} {
+		u := v
+		if err := u.UnmarshalBinary(b); err == nil {
+			t.Errorf("UnmarshalBinary(%v): expected error", b)
+		}
+		if u != v {
+			t.Errorf("UnmarshalBinary(%v): version unexpectedly modified to %v", b, u)
+		}
+	}
+}
+
+func TestSQL(t *testing.T) {
+	v := Version{0, 123, 1, 1234567, Comma}
+	if value, err := v.Value(); value != "0, 123, 1, 1234567" || err != nil {
+		t.Errorf("%v.Value(): expected (%q, %v), got (%v, %v)", v, "0, 123, 1, 1234567", nil, value, err)
+	}
+	for _, src := range []any{"0, 123, 1, 1234567", []byte("0, 123, 1, 1234567")} {
+		var u Version
+		if err := u.Scan(src); err != nil || u != v {
+			t.Errorf("Scan(%v): expected (%v, %v), got (%v, %v)", src, v, nil, u, err)
+		}
+	}
+	u := v
+	if err := u.Scan(nil); err != nil || u != (Version{}) {
+		t.Errorf("Scan(nil): expected (%v, %v), got (%v, %v)", Version{}, nil, u, err)
+	}
+	var serr *SyntaxError
+	if err := u.Scan("0.123.1"); !errors.As(err, &serr) || serr.Offset != 7 || serr.Input != "0.123.1" {
+		t.Errorf("Scan(invalid): expected error at position 7 with input, got %v", err)
+	}
+	if err := u.Scan("0.123.x"); err == nil || !strings.Contains(err.Error(), `"0.123.x"`) {
+		t.Errorf("Scan(invalid): expected error containing input, got %v", err)
+	}
+	if err := u.Scan(42); err == nil {
+		t.Errorf("Scan(42): expected error")
+	}
+}
+
+type testBuild struct {
+	name    string
+	version string
+}
+
+func (b testBuild) String() string { return b.version }
+
+func TestParseStringer(t *testing.T) {
+	v, err := ParseStringer(testBuild{"studio", "0.123.1.1234567"}, Any)
+	if u := (Version{0, 123, 1, 1234567, Dot}); v != u || err != nil {
+		t.Errorf("ParseStringer: expected (%v, %v), got (%v, %v)", u, nil, v, err)
+	}
+	if _, err := ParseStringer(testBuild{"studio", "0.123.1.1234567x"}, Any); !errors.Is(err, ErrSyntax) {
+		t.Errorf("ParseStringer(trailing): expected error %v, got %v", ErrSyntax, err)
+	}
+	v = Version{0, 123, 1, 1234567, Comma}
+	if u, err := ParseStringer(v, Comma); u != v || err != nil {
+		t.Errorf("ParseStringer(Version): expected (%v, %v), got (%v, %v)", v, nil, u, err)
+	}
+}
+
+func TestHexCommit(t *testing.T) {
+	tests := []struct {
+		v Version
+		s string
+	}{
+		{Version{0, 123, 1, 1234567, Dot}, "0.123.1.12d687"},
+		{Version{0, 123, 1, 1234567, Comma}, "0, 123, 1, 12d687"},
+		{Version{0, 0, 0, 0, Dot}, "0.0.0.0"},
+		{Version{1, 2, 3, 255, Dot}, "1.2.3.ff"},
+	}
+	for _, test := range tests {
+		if s := test.v.HexCommitString(); s != test.s {
+			t.Errorf("%v.HexCommitString(): expected %q, got %q", test.v, test.s, s)
+		}
+		if v, err := ParseHexCommit(test.s, Any); v != test.v || err != nil {
+			t.Errorf("ParseHexCommit(%q): expected (%v, %v), got (%v, %v)", test.s, test.v, nil, v, err)
+		}
+	}
+	if v, err := ParseHexCommit("0.123.1.12D687", Dot); v != (Version{0, 123, 1, 1234567, Dot}) || err != nil {
+		t.Errorf("ParseHexCommit(upper): expected (%v, %v), got (%v, %v)", Version{0, 123, 1, 1234567, Dot}, nil, v, err)
+	}
+	for _, test := range []struct {
+		s string
+		n int
+	}{
+		{"", 0},
+		{"12d687", 6},
+		{"0.123.1.", 8},
+		{"0.123.1.xyz", 8},
+		{"0.123.1.-1", 8},
+		{"0.123.1.+1", 8},
+		{"0.123.x.1", 6},
+		{"0.123.1.1.2", 9},
+	} {
+		var serr *SyntaxError
+		if _, err := ParseHexCommit(test.s, Dot); !errors.As(err, &serr) || serr.Offset != test.n || serr.Input != test.s {
+			t.Errorf("ParseHexCommit(%q): expected *SyntaxError at position %d, got %v", test.s, test.n, err)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	vs := []Version{
+		{0, 124, 0, 0, Dot},
+		{0, 123, 1, 1234567, Comma},
+		{1, 0, 0, 0, Dot},
+		{0, 123, 0, 9, Dot},
+		{0, 0, 0, 0, Any},
+	}
+	expected := []Version{
+		{0, 0, 0, 0, Any},
+		{0, 123, 0, 9, Dot},
+		{0, 123, 1, 1234567, Comma},
+		{0, 124, 0, 0, Dot},
+		{1, 0, 0, 0, Dot},
+	}
+	Sort(vs)
+	if !slices.Equal(vs, expected) {
+		t.Errorf("Sort: expected %v, got %v", expected, vs)
+	}
+	if Versions(vs).Less(1, 1) {
+		t.Errorf("Versions.Less: expected version to not be less than itself")
+	}
+	if !sort.IsSorted(Versions(vs)) {
+		t.Errorf("Versions: expected sorted")
+	}
+}
+
+func TestEqualLessGreater(t *testing.T) {
+	tests := []struct {
+		v, u               Version
+		eq, lt, le, gt, ge bool
+	}{
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 123, 1, 1234567, Comma}, true, false, true, false, true},
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 123, 1, 1234568, Dot}, false, true, true, false, false},
+		{Version{0, 124, 0, 0, Dot}, Version{0, 123, 1, 1234567, Dot}, false, false, false, true, true},
+	}
+	for _, test := range tests {
+		if r := test.v.Equal(test.u); r != test.eq {
+			t.Errorf("%v.Equal(%v): expected %t, got %t", test.v, test.u, test.eq, r)
+		}
+		if r := test.v.Less(test.u); r != test.lt {
+			t.Errorf("%v.Less(%v): expected %t, got %t", test.v, test.u, test.lt, r)
+		}
+		if r := test.v.LessOrEqual(test.u); r != test.le {
+			t.Errorf("%v.LessOrEqual(%v): expected %t, got %t", test.v, test.u, test.le, r)
+		}
+		if r := test.v.Greater(test.u); r != test.gt {
+			t.Errorf("%v.Greater(%v): expected %t, got %t", test.v, test.u, test.gt, r)
+		}
+		if r := test.v.GreaterOrEqual(test.u); r != test.ge {
+			t.Errorf("%v.GreaterOrEqual(%v): expected %t, got %t", test.v, test.u, test.ge, r)
+		}
+	}
+}
+
+func TestValidateManifest(t *testing.T) {
+	tests := []struct {
+		vs     []Version
+		index  int
+		reason string
+	}{
+		{[]Version{{0, 1, 0, 0, Dot}, {0, 2, 0, 0, Dot}, {0, 2, 0, 1, Dot}}, -1, ""},
+		{nil, -1, ""},
+		{[]Version{{0, 1, 0, 0, Dot}, {0, 3, 0, 0, Dot}, {0, 2, 0, 0, Dot}}, 2, "0.2.0.0 is not higher than 0.3.0.0"},
+		{[]Version{{0, 1, 0, 0, Dot}, {0, 1, 0, 0, Dot}}, 1, "0.1.0.0 is not higher than 0.1.0.0"},
+		{[]Version{{0, 1, 0, 0, Dot}, {0, 2, 0, 0, Comma}}, 1, "format 2 does not match required format 1"},
+	}
+	for _, test := range tests {
+		err := ValidateManifest(test.vs, Dot)
+		if test.index < 0 {
+			if err != nil {
+				t.Errorf("ValidateManifest(%v): unexpected error %v", test.vs, err)
+			}
+			continue
+		}
+		var merr *ManifestError
+		if !errors.As(err, &merr) {
+			t.Errorf("ValidateManifest(%v): expected *ManifestError, got %v", test.vs, err)
+			continue
+		}
+		if merr.Index != test.index || merr.Reason != test.reason {
+			t.Errorf("ValidateManifest(%v): expected (%d, %q), got (%d, %q)", test.vs, test.index, test.reason, merr.Index, merr.Reason)
+		}
+	}
+}
+
+func TestMaxMin(t *testing.T) {
+	vs := []Version{
+		{0, 123, 1, 1234567, Dot},
+		{0, 124, 0, 0, Comma},
+		{0, 122, 0, 0, Dot},
+		{0, 124, 0, 0, Dot},
+		{0, 122, 0, 0, Comma},
+	}
+	if v := Max(vs...); v != vs[1] {
+		t.Errorf("Max: expected %v, got %v", vs[1], v)
+	}
+	if v := Min(vs...); v != vs[2] {
+		t.Errorf("Min: expected %v, got %v", vs[2], v)
+	}
+	if v := Max(); v != (Version{}) {
+		t.Errorf("Max(): expected zero version, got %v", v)
+	}
+	if v := Min(); v != (Version{}) {
+		t.Errorf("Min(): expected zero version, got %v", v)
+	}
+}
+
+func TestPackStrict(t *testing.T) {
+	v := Version{1, 123, 2, 1234567, Dot}
+	p, err := v.PackStrict()
+	if expected := uint64(1)<<56 | 123<<40 | 2<<32 | 1234567; p != expected || err != nil {
+		t.Errorf("%v.PackStrict(): expected (%#x, %v), got (%#x, %v)", v, expected, nil, p, err)
+	}
+	if q, ok := v.Uint64(); q != p || !ok {
+		t.Errorf("%v.Uint64(): expected (%#x, %t), got (%#x, %t)", v, p, true, q, ok)
+	}
+	if a, _ := (Version{0, 123, 1, 1 << 31, Dot}).PackStrict(); a >= p {
+		t.Errorf("PackStrict: expected packed order to match semantic order")
+	}
+	tests := []struct {
+		v   Version
+		msg string
+	}{
+		{Version{256, 0, 0, 0, Dot}, "Generation 256 overflows 8-bit field"},
+		{Version{0, 65536, 0, 0, Dot}, "Version 65536 overflows 16-bit field"},
+		{Version{0, 0, 256, 0, Dot}, "Patch 256 overflows 8-bit field"},
+		{Version{0, 0, 0, 1 << 32, Dot}, "Commit 4294967296 overflows 32-bit field"},
+		{Version{0, 0, -1, 0, Dot}, "Patch -1 overflows 8-bit field"},
+	}
+	for _, test := range tests {
+		if _, err := test.v.PackStrict(); err == nil || err.Error() != test.msg {
+			t.Errorf("%v.PackStrict(): expected error %q, got %v", test.v, test.msg, err)
+		}
+		if _, ok := test.v.Uint64(); ok {
+			t.Errorf("%v.Uint64(): expected not ok", test.v)
+		}
+	}
+}
+
+func TestClamp(t *testing.T) {
+	lo := Version{0, 123, 0, 0, Dot}
+	hi := Version{0, 124, 0, 0, Dot}
+	tests := []struct {
+		v, u Version
+	}{
+		{Version{0, 123, 1, 1234567, Comma}, Version{0, 123, 1, 1234567, Comma}},
+		{Version{0, 123, 0, 0, Comma}, Version{0, 123, 0, 0, Comma}},
+		{Version{0, 122, 0, 0, Comma}, lo},
+		{Version{0, 125, 0, 0, Comma}, hi},
+	}
+	for _, test := range tests {
+		if u := test.v.Clamp(lo, hi); u != test.u {
+			t.Errorf("%v.Clamp(%v, %v): expected %v, got %v", test.v, lo, hi, test.u, u)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Clamp(hi, lo): expected panic")
+		}
+	}()
+	lo.Clamp(hi, lo)
+}
+
+func TestParseAudit(t *testing.T) {
+	tests := []struct {
+		s        string
+		f        Format
+		v        Version
+		warnings []string
+		e        error
+	}{
+		{"0.123.1.1234567", Dot, Version{0, 123, 1, 1234567, Dot}, nil, nil},
+		{"0.123.1.1234567", Any, Version{0, 123, 1, 1234567, Dot}, []string{"guessed dot separator"}, nil},
+		{"0, 123, 1, 1234567", Any, Version{0, 123, 1, 1234567, Comma}, []string{"guessed comma separator"}, nil},
+		{" 0.123.1.1234567\n", Dot, Version{0, 123, 1, 1234567, Dot}, []string{"trimmed leading whitespace", "trimmed trailing whitespace"}, nil},
+		{"0.0123.1.01234567", Dot, Version{0, 123, 1, 1234567, Dot}, []string{"accepted leading zeros in version", "accepted leading zeros in commit"}, nil},
+		{"0.123.1.1234567 ", Comma, Version{}, []string{"trimmed trailing whitespace"}, ErrSyntax},
+		{"0.123.1", Dot, Version{}, nil, io.ErrUnexpectedEOF},
+	}
+	for _, test := range tests {
+		v, warnings, err := ParseAudit(test.s, test.f)
+		if v != test.v || !errors.Is(err, test.e) {
+			t.Errorf("ParseAudit(%q, %s): expected (%v, %v), got (%v, %v)", test.s, fmtstr[test.f], test.v, test.e, v, err)
+		}
+		if !slices.Equal(warnings, test.warnings) {
+			t.Errorf("ParseAudit(%q, %s): expected warnings %q, got %q", test.s, fmtstr[test.f], test.warnings, warnings)
+		}
+	}
+}
+
+func TestParsePartial(t *testing.T) {
+	tests := []struct {
+		s     string
+		f     Format
+		v     Version
+		count int
+		n     int
+		e     error
+	}{
+		{"0.123", Any, Version{0, 123, 0, 0, Dot}, 2, 5, nil},
+		{"0.123.1", Dot, Version{0, 123, 1, 0, Dot}, 3, 7, nil},
+		{"0.123.1.1234567", Any, Version{0, 123, 1, 1234567, Dot}, 4, 15, nil},
+		{"0.123.1.1234567.8", Any, Version{0, 123, 1, 1234567, Dot}, 4, 15, nil},
+		{"0, 123", Any, Version{0, 123, 0, 0, Comma}, 2, 6, nil},
+		{"0, 123, 1", Comma, Version{0, 123, 1, 0, Comma}, 3, 9, nil},
+		{"0.123.", Any, Version{0, 123, 0, 0, Dot}, 2, 5, nil},
+		{"0.123.x", Any, Version{0, 123, 0, 0, Dot}, 2, 5, nil},
+		{"0.123, 1", Any, Version{0, 123, 0, 0, Dot}, 2, 5, nil},
+		{"12", Any, Version{12, 0, 0, 0, Dot}, 1, 2, nil},
+		{"12", Comma, Version{12, 0, 0, 0, Comma}, 1, 2, nil},
+		{"12.34", Comma, Version{12, 0, 0, 0, Comma}, 1, 2, nil},
+		{"", Any, Version{}, 0, 0, io.ErrUnexpectedEOF},
+		{"x", Any, Version{}, 0, 0, ErrSyntax},
+	}
+	for _, test := range tests {
+		v, count, n, err := ParsePartial([]byte(test.s), test.f)
+		if v != test.v || count != test.count || n != test.n || !errors.Is(err, test.e) {
+			t.Errorf("ParsePartial(%q, %s): expected (%v, %d, %d, %v), got (%v, %d, %d, %v)", test.s, fmtstr[test.f], test.v, test.count, test.n, test.e, v, count, n, err)
+		}
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	if v := MustParse("0.123.1.1234567", Any); v != (Version{0, 123, 1, 1234567, Dot}) {
+		t.Errorf("MustParse: expected %v, got %v", Version{0, 123, 1, 1234567, Dot}, v)
+	}
+	tests := []struct {
+		s   string
+		msg string
+	}{
+		{"0.123.x.1234567", `rbxver: MustParse("0.123.x.1234567"): invalid syntax at position 6 in "0.123.x.1234567"`},
+		{"0.123.1", `rbxver: MustParse("0.123.1"): unexpected EOF`},
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if r := recover(); r != test.msg {
+					t.Errorf("MustParse(%q): expected panic %q, got %v", test.s, test.msg, r)
+				}
+			}()
+			MustParse(test.s, Any)
+		}()
+	}
+}
+
+func TestNearestByTime(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := map[Version]time.Time{
+		{0, 121, 0, 1, Dot}: base,
+		{0, 122, 0, 1, Dot}: base.Add(10 * time.Hour),
+		{0, 123, 0, 1, Dot}: base.Add(20 * time.Hour),
+		{0, 124, 0, 1, Dot}: base.Add(40 * time.Hour),
+	}
+	tests := []struct {
+		target time.Time
+		v      Version
+	}{
+		{base.Add(-time.Hour), Version{0, 121, 0, 1, Dot}},
+		{base.Add(11 * time.Hour), Version{0, 122, 0, 1, Dot}},
+		{base.Add(19 * time.Hour), Version{0, 123, 0, 1, Dot}},
+		{base.Add(15 * time.Hour), Version{0, 122, 0, 1, Dot}},
+		{base.Add(30 * time.Hour), Version{0, 123, 0, 1, Dot}},
+		{base.Add(100 * time.Hour), Version{0, 124, 0, 1, Dot}},
+	}
+	for _, test := range tests {
+		if v, ok := NearestByTime(m, test.target); v != test.v || !ok {
+			t.Errorf("NearestByTime(%v): expected (%v, %t), got (%v, %t)", test.target, test.v, true, v, ok)
+		}
+	}
+	if _, ok := NearestByTime(nil, base); ok {
+		t.Errorf("NearestByTime(nil): expected not ok")
+	}
+}
+
+func TestParseReader(t *testing.T) {
+	for _, test := range tests {
+		r := strings.NewReader(test.s)
+		v, n, err := ParseReader(r, test.f)
+		if v != test.v || n != test.n || !errors.Is(err, test.e) {
+			t.Errorf("ParseReader(%q, %s): expected (%v, %d, %v), got (%v, %d, %v)", test.s, fmtstr[test.f], test.v, test.n, test.e, v, n, err)
+		}
+		if test.e == nil {
+			if rest, _ := io.ReadAll(r); string(rest) != test.s[n:] {
+				t.Errorf("ParseReader(%q, %s): expected remaining %q, got %q", test.s, fmtstr[test.f], test.s[n:], rest)
+			}
+		}
+		if _, bn, berr := ParseBytes([]byte(test.s), test.f); n != bn || errors.Is(err, ErrSyntax) != errors.Is(berr, ErrSyntax) {
+			t.Errorf("ParseReader(%q, %s): expected same result as ParseBytes (%d, %v), got (%d, %v)", test.s, fmtstr[test.f], bn, berr, n, err)
+		}
+	}
+
+	// Without io.ByteScanner, exactly one trailing byte is consumed.
+	r := io.MultiReader(strings.NewReader("0.123.1.1234567 rest of line"))
+	v, n, err := ParseReader(r, Any)
+	if u := (Version{0, 123, 1, 1234567, Dot}); v != u || n != 15 || err != nil {
+		t.Errorf("ParseReader(MultiReader): expected (%v, %d, %v), got (%v, %d, %v)", u, 15, nil, v, n, err)
+	}
+	if rest, _ := io.ReadAll(r); string(rest) != "rest of line" {
+		t.Errorf("ParseReader(MultiReader): expected remaining %q, got %q", "rest of line", rest)
+	}
+
+	for _, test := range []struct {
+		s string
+		f Format
+		e error
+	}{
+		{"", Any, io.ErrUnexpectedEOF},
+		{"0.123.1", Any, io.ErrUnexpectedEOF},
+		{"0.123.1.", Any, io.ErrUnexpectedEOF},
+		{"0.1.2.", Dot, io.ErrUnexpectedEOF},
+		{"0,", Any, io.ErrUnexpectedEOF},
+		{"0x", Dot, io.ErrUnexpectedEOF},
+		{"0.123.x", Any, ErrSyntax},
+		{"0,123", Any, ErrSyntax},
+		{"0,1", Any, ErrSyntax},
+		{"0, ", Comma, ErrSyntax},
+		{"0, 1,x", Comma, ErrSyntax},
+		{"0.1,2.3", Any, ErrSyntax},
+		{"0.99999999999999999999.1.1", Dot, ErrOverflow},
+	} {
+		_, n, err := ParseReader(strings.NewReader(test.s), test.f)
+		_, bn, berr := ParseBytes([]byte(test.s), test.f)
+		if !errors.Is(err, test.e) || !errors.Is(berr, test.e) || n != bn {
+			t.Errorf("ParseReader(%q, %s): expected (%d, %v) like ParseBytes, got (%d, %v)", test.s, fmtstr[test.f], bn, berr, n, err)
+		}
+		var serr *SyntaxError
+		if errors.As(err, &serr) && (serr.Offset != n || !strings.HasPrefix(test.s, serr.Input)) {
+			t.Errorf("ParseReader(%q, %s): expected error at %d with input read, got %#v", test.s, fmtstr[test.f], n, serr)
+		}
+	}
+}
+
+func TestFindVersion(t *testing.T) {
+	tests := []struct {
+		s          string
+		f          Format
+		v          Version
+		start, end int
+		ok         bool
+	}{
+		{"Studio version, v0.123.1.1234567 (64-bit)", Any, Version{0, 123, 1, 1234567, Dot}, 17, 32, true},
+		{"Studio version, 0, 123, 1, 1234567 (64-bit)", Any, Version{0, 123, 1, 1234567, Comma}, 16, 34, true},
+		{"Studio version, 0, 123, 1, 1234567 (64-bit)", Comma, Version{0, 123, 1, 1234567, Comma}, 16, 34, true},
+		{"Studio version, 0, 123, 1, 1234567 (64-bit)", Dot, Version{}, 0, 0, false},
+		{"Studio (64-bit)", Any, Version{}, 0, 0, false},
+	}
+	for _, test := range tests {
+		v, start, end, ok := FindVersion(test.s, test.f)
+		if v != test.v || start != test.start || end != test.end || ok != test.ok {
+			t.Errorf("FindVersion(%q, %s): expected (%v, %d, %d, %t), got (%v, %d, %d, %t)", test.s, fmtstr[test.f], test.v, test.start, test.end, test.ok, v, start, end, ok)
+		}
+	}
+}
+
+func TestUnion(t *testing.T) {
+	a := []Version{{0, 3, 0, 0, Dot}, {0, 1, 0, 0, Dot}}
+	b := []Version{{0, 2, 0, 0, Dot}, {0, 1, 0, 0, Comma}}
+	c := []Version{{0, 3, 0, 0, Comma}, {0, 4, 0, 0, Dot}}
+	expected := []Version{
+		{0, 1, 0, 0, Dot},
+		{0, 2, 0, 0, Dot},
+		{0, 3, 0, 0, Dot},
+		{0, 4, 0, 0, Dot},
+	}
+	if u := Union(a, b, c); !slices.Equal(u, expected) {
+		t.Errorf("Union: expected %v, got %v", expected, u)
+	}
+	if !slices.Equal(a, []Version{{0, 3, 0, 0, Dot}, {0, 1, 0, 0, Dot}}) {
+		t.Errorf("Union: input unexpectedly modified to %v", a)
+	}
+	if u := Union(); len(u) != 0 {
+		t.Errorf("Union(): expected empty, got %v", u)
+	}
+}
+
+func TestFindAllVersions(t *testing.T) {
+	s := `Changelog:
+- 0.123.1.1234567: fixed crash
+- 0, 124, 0, 7654321: new feature
+- 0.125.0.1.2.3.4: odd build
+- 12345.`
+	expected := []Version{
+		{0, 123, 1, 1234567, Dot},
+		{0, 124, 0, 7654321, Comma},
+		{0, 125, 0, 1, Dot},
+	}
+	if vs := FindAllVersions(s, Any); !slices.Equal(vs, expected) {
+		t.Errorf("FindAllVersions(Any): expected %v, got %v", expected, vs)
+	}
+	expected = []Version{{0, 124, 0, 7654321, Comma}}
+	if vs := FindAllVersions(s, Comma); !slices.Equal(vs, expected) {
+		t.Errorf("FindAllVersions(Comma): expected %v, got %v", expected, vs)
+	}
+	if vs := FindAllVersions("no versions", Any); vs == nil || len(vs) != 0 {
+		t.Errorf("FindAllVersions(none): expected empty non-nil slice, got %#v", vs)
+	}
+}
+
+func TestPreferredFormat(t *testing.T) {
+	tests := []struct {
+		s string
+		f Format
+	}{
+		{"0.123.1.1234567", Dot},
+		{"0, 123, 1, 1234567", Comma},
+		{"invalid", Dot},
+	}
+	for _, test := range tests {
+		if f := Parse(test.s, Any).PreferredFormat(); f != test.f {
+			t.Errorf("Parse(%q).PreferredFormat(): expected %s, got %s", test.s, fmtstr[test.f], fmtstr[f])
+		}
+	}
+	if f := (Version{Format: 7}).PreferredFormat(); f != Dot {
+		t.Errorf("PreferredFormat(invalid): expected %s, got %s", fmtstr[Dot], fmtstr[f])
+	}
+}
+
+func TestParseWithStripInvisible(t *testing.T) {
+	s := "\u200b0.123\u200c.1.1234567\ufeff"
+	if v := ParseWith(s, Any, StripInvisible); v != (Version{0, 123, 1, 1234567, Dot}) {
+		t.Errorf("ParseWith(%q, StripInvisible): expected %v, got %v", s, Version{0, 123, 1, 1234567, Dot}, v)
+	}
+	if v := Parse(s, Any); v != (Version{}) {
+		t.Errorf("Parse(%q): expected zero version, got %v", s, v)
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	tests := []struct {
+		s      string
+		offset int
+	}{
+		{"0.123.x.1234567", 6},
+		{"0.123.1.1234567 ", 15},
+		{"x", 0},
+	}
+	for _, test := range tests {
+		_, err := ParseAnyOf(test.s, Dot)
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Errorf("ParseAnyOf(%q): expected *SyntaxError, got %v", test.s, err)
+			continue
+		}
+		if !errors.Is(err, ErrSyntax) {
+			t.Errorf("ParseAnyOf(%q): expected error to wrap %v", test.s, ErrSyntax)
+		}
+		if serr.Offset != test.offset || serr.Input != test.s {
+			t.Errorf("ParseAnyOf(%q): expected offset %d and input %q, got %d and %q", test.s, test.offset, test.s, serr.Offset, serr.Input)
+		}
+		if msg := "invalid syntax at position " + strconv.Itoa(test.offset) + " in " + strconv.Quote(test.s); err.Error() != msg {
+			t.Errorf("ParseAnyOf(%q): expected message %q, got %q", test.s, msg, err.Error())
+		}
+	}
+	if _, err := ParseAnyOf("0.123.1", Dot); err != io.ErrUnexpectedEOF {
+		t.Errorf("ParseAnyOf(short): expected error %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	vs := []Version{
+		{0, 0, 0, 0, Dot},
+		{0, 0, 0, 1, Dot},
+		{0, 123, 0, 1 << 31, Dot},
+		{0, 123, 1, 0, Dot},
+		{0, 124, 0, 0, Dot},
+		{1, 0, 0, 0, Dot},
+		{127, 65535, 255, 1<<32 - 1, Dot},
+	}
+	for i := 1; i < len(vs); i++ {
+		a, aok := vs[i-1].Timeline()
+		b, bok := vs[i].Timeline()
+		if !aok || !bok {
+			t.Errorf("Timeline(%v, %v): expected ok", vs[i-1], vs[i])
+		}
+		if a >= b {
+			t.Errorf("Timeline: expected %v (%d) < %v (%d)", vs[i-1], a, vs[i], b)
+		}
+	}
+	for _, v := range []Version{
+		{128, 0, 0, 0, Dot},
+		{0, 65536, 0, 0, Dot},
+		{0, 0, 0, 1 << 32, Dot},
+	} {
+		if pos, ok := v.Timeline(); pos != math.MaxInt64 || ok {
+			t.Errorf("%v.Timeline(): expected (%d, %t), got (%d, %t)", v, int64(math.MaxInt64), false, pos, ok)
+		}
+	}
+}
+
+func TestParseOverflow(t *testing.T) {
+	big := "99999999999999999999"
+	tests := []struct {
+		s    string
+		n    int
+		name string
+	}{
+		{big + ".123.1.1234567", 0, "Generation"},
+		{"0." + big + ".1.1234567", 2, "Version"},
+		{"0.123." + big + ".1234567", 6, "Patch"},
+		{"0.123.1." + big, 8, "Commit"},
+		{"0.123.1." + strconv.Itoa(MaxComponent), 8, "Commit"},
+	}
+	for _, test := range tests {
+		_, n, err := ParseBytes([]byte(test.s), Any)
+		if !errors.Is(err, ErrOverflow) {
+			t.Errorf("ParseBytes(%q): expected error %v, got %v", test.s, ErrOverflow, err)
+		} else if msg := "value out of range in " + test.name; err.Error() != msg {
+			t.Errorf("ParseBytes(%q): expected message %q, got %q", test.s, msg, err.Error())
+		}
+		if errors.Is(err, ErrSyntax) {
+			t.Errorf("ParseBytes(%q): overflow unexpectedly wraps %v", test.s, ErrSyntax)
+		}
+		if n != test.n {
+			t.Errorf("ParseBytes(%q): expected bytes %d, got %d", test.s, test.n, n)
+		}
+		if _, err := ParseAnyOf(test.s, Any); !errors.Is(err, ErrOverflow) {
+			t.Errorf("ParseAnyOf(%q): expected error %v, got %v", test.s, ErrOverflow, err)
+		}
+		if _, _, err := ParseReader(strings.NewReader(test.s), Any); !errors.Is(err, ErrOverflow) {
+			t.Errorf("ParseReader(%q): expected error %v, got %v", test.s, ErrOverflow, err)
+		}
+	}
+	if _, _, _, err := ParsePartial([]byte("0."+big), Any); !errors.Is(err, ErrOverflow) {
+		t.Errorf("ParsePartial: expected error %v, got %v", ErrOverflow, err)
+	}
+}
+
+func TestEncodedLen(t *testing.T) {
+	for _, v := range []Version{
+		{0, 0, 0, 0, Any},
+		{0, 0, 0, 0, Comma},
+		{0, 123, 1, 1234567, Dot},
+		{0, 123, 1, 1234567, Comma},
+		{9, 10, 99, 100, Dot},
+		{-1, -10, 0, 0, Dot},
+		{math.MaxInt, math.MaxInt, math.MaxInt, math.MaxInt, Comma},
+		{1, 2, 3, 4, 3},
+	} {
+		if n := v.EncodedLen(); n != len(v.String()) {
+			t.Errorf("%#v.EncodedLen(): expected %d, got %d", v, len(v.String()), n)
+		}
+	}
+}
+
+func TestComponents(t *testing.T) {
+	v := Version{0, 123, 1, 1234567, Comma}
+	if c := v.Components(); c != [4]int{0, 123, 1, 1234567} {
+		t.Errorf("Components(): expected %v, got %v", [4]int{0, 123, 1, 1234567}, c)
+	}
+	if u := FromComponents(v.Components(), v.Format); u != v {
+		t.Errorf("FromComponents(%v, Comma): expected %v, got %v", v.Components(), v, u)
+	}
+	if u := FromComponents([4]int{1, 2, 3, 4}, Dot); u != (Version{1, 2, 3, 4, Dot}) {
+		t.Errorf("FromComponents(%v, Dot): expected %v, got %v", [4]int{1, 2, 3, 4}, Version{1, 2, 3, 4, Dot}, u)
+	}
+}
+
+func TestBisect(t *testing.T) {
+	lo := Version{0, 123, 1, 1000, Dot}
+	hi := Version{0, 123, 1, 2000, Dot}
+	for _, boundary := range []int{1000, 1001, 1234, 1999, 2000} {
+		var calls int
+		v, ok := Bisect(lo, hi, func(v Version) bool {
+			calls++
+			return v.Commit <= boundary
+		})
+		if want := (Version{0, 123, 1, boundary, Dot}); v != want || !ok {
+			t.Errorf("Bisect(%v, %v) with boundary %d: expected (%v, true), got (%v, %t)", lo, hi, boundary, want, v, ok)
+		}
+		if calls > 11 {
+			t.Errorf("Bisect(%v, %v) with boundary %d: expected at most 11 calls, got %d", lo, hi, boundary, calls)
+		}
+	}
+	for _, bounds := range [][2]int{{0, MaxComponent}, {math.MinInt, math.MaxInt}, {-5, 1000}} {
+		lo, hi := Version{Commit: bounds[0]}, Version{Commit: bounds[1]}
+		v, ok := Bisect(lo, hi, func(v Version) bool { return v.Commit < 100 })
+		if v != (Version{Commit: 99}) || !ok {
+			t.Errorf("Bisect(%d, %d): expected (%v, true), got (%v, %t)", bounds[0], bounds[1], Version{Commit: 99}, v, ok)
+		}
+	}
+	if v, ok := Bisect(Version{}, Version{Commit: MaxComponent}, func(Version) bool { return true }); v != (Version{Commit: MaxComponent}) || !ok {
+		t.Errorf("Bisect(0, MaxComponent): expected (%v, true), got (%v, %t)", Version{Commit: MaxComponent}, v, ok)
+	}
+	never := func(Version) bool { return false }
+	always := func(Version) bool { return true }
+	if v, ok := Bisect(lo, hi, never); v != (Version{}) || ok {
+		t.Errorf("Bisect(%v, %v) never true: expected (%v, false), got (%v, %t)", lo, hi, Version{}, v, ok)
+	}
+	if v, ok := Bisect(lo, lo, always); v != lo || !ok {
+		t.Errorf("Bisect(%v, %v): expected (%v, true), got (%v, %t)", lo, lo, lo, v, ok)
+	}
+	for _, h := range []Version{{0, 123, 1, 999, Dot}, {0, 123, 2, 2000, Dot}, {0, 124, 1, 2000, Dot}, {1, 123, 1, 2000, Dot}} {
+		if v, ok := Bisect(lo, h, always); v != (Version{}) || ok {
+			t.Errorf("Bisect(%v, %v): expected (%v, false), got (%v, %t)", lo, h, Version{}, v, ok)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	v := Version{12, 34, 56, 78, Comma}
+	for n, want := range []Version{
+		{0, 0, 0, 0, Comma},
+		{12, 0, 0, 0, Comma},
+		{12, 34, 0, 0, Comma},
+		{12, 34, 56, 0, Comma},
+		{12, 34, 56, 78, Comma},
+	} {
+		if u := v.Truncate(n); u != want {
+			t.Errorf("Truncate(%d): expected %v, got %v", n, want, u)
+		}
+	}
+	for _, n := range []int{-1, 5} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Truncate(%d): expected panic", n)
+				}
+			}()
+			v.Truncate(n)
+		}()
+	}
+}
+
+func TestDump(t *testing.T) {
+	tests := []struct {
+		v    Version
+		dump string
+	}{
+		{Version{0, 123, 1, 1234567, Dot}, "Generation: 0\nVersion:    123\nPatch:      1\nCommit:     1234567\nFormat:     Dot"},
+		{Version{0, 0, 0, 0, Any}, "Generation: 0\nVersion:    0\nPatch:      0\nCommit:     0\nFormat:     Any"},
+		{Version{1, -2, 3, 4, Comma}, "Generation: 1\nVersion:    -2\nPatch:      3\nCommit:     4\nFormat:     Comma"},
+		{Version{1, 2, 3, 4, 5}, "Generation: 1\nVersion:    2\nPatch:      3\nCommit:     4\nFormat:     Format(5)"},
+	}
+	for _, test := range tests {
+		if dump := test.v.Dump(); dump != test.dump {
+			t.Errorf("%#v.Dump(): expected %q, got %q", test.v, test.dump, dump)
+		}
+	}
+}
+
+func TestIsZero(t *testing.T) {
+	tests := []struct {
+		v    Version
+		zero bool
+	}{
+		{Zero, true},
+		{Version{}, true},
+		{Version{0, 0, 0, 0, Dot}, true},
+		{Version{0, 0, 0, 0, Comma}, true},
+		{Parse("invalid", Any), true},
+		{Parse("0.0.0.0", Any), true},
+		{Version{0, 0, 0, 1, Any}, false},
+		{Version{1, 0, 0, 0, Any}, false},
+		{Version{0, 123, 1, 1234567, Dot}, false},
+	}
+	for _, test := range tests {
+		if zero := test.v.IsZero(); zero != test.zero {
+			t.Errorf("%#v.IsZero(): expected %t, got %t", test.v, test.zero, zero)
+		}
+	}
+}
+
+func TestParseBounded(t *testing.T) {
+	limits := [4]int{1, 3, 2, 7}
+	for _, s := range []string{"0.123.1.1234567", "0, 123, 12, 1234567", "9.999.99.9999999", "0.0.0.0"} {
+		v := Parse(s, Any)
+		if u, err := ParseBounded(s, Any, limits); u != v || err != nil {
+			t.Errorf("ParseBounded(%q, Any, %v): expected (%v, %v), got (%v, %v)", s, limits, v, nil, u, err)
+		}
+	}
+	tests := []struct {
+		s   string
+		err string
+	}{
+		{"10.123.1.1234567", "value out of range in Generation: more than 1 digits"},
+		{"00.123.1.1234567", "value out of range in Generation: more than 1 digits"},
+		{"0.1234.1.1234567", "value out of range in Version: more than 3 digits"},
+		{"0, 123, 100, 1234567", "value out of range in Patch: more than 2 digits"},
+		{"0.123.1.12345678", "value out of range in Commit: more than 7 digits"},
+	}
+	for _, test := range tests {
+		v, err := ParseBounded(test.s, Any, limits)
+		if v != (Version{}) || !errors.Is(err, ErrOverflow) || err.Error() != test.err {
+			t.Errorf("ParseBounded(%q, Any, %v): expected (%v, %q), got (%v, %v)", test.s, limits, Version{}, test.err, v, err)
+		}
+	}
+	if v, err := ParseBounded("100.1000.100.12345678", Any, [4]int{}); v != (Version{100, 1000, 100, 12345678, Dot}) || err != nil {
+		t.Errorf("ParseBounded(unbounded): expected (%v, %v), got (%v, %v)", Version{100, 1000, 100, 12345678, Dot}, nil, v, err)
+	}
+	if _, err := ParseBounded("0.123.1", Any, limits); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ParseBounded(%q, Any, %v): expected error %v, got %v", "0.123.1", limits, io.ErrUnexpectedEOF, err)
+	}
+}
+
+func TestCompareN(t *testing.T) {
+	tests := []struct {
+		v, u Version
+		n    int
+		c    int
+	}{
+		{Version{1, 2, 3, 4, Dot}, Version{1, 2, 9, 9, Comma}, 2, 0},
+		{Version{1, 2, 3, 4, Dot}, Version{1, 2, 9, 9, Dot}, 3, -1},
+		{Version{1, 2, 3, 4, Dot}, Version{1, 2, 3, 1, Dot}, 3, 0},
+		{Version{1, 2, 3, 4, Dot}, Version{1, 2, 3, 1, Dot}, 4, 1},
+		{Version{1, 2, 3, 4, Dot}, Version{1, 9, 3, 4, Dot}, 1, 0},
+		{Version{2, 0, 0, 0, Dot}, Version{1, 9, 9, 9, Dot}, 1, 1},
+		{Version{1, 2, 3, 4, Dot}, Version{1, 2, 3, 4, Dot}, 4, 0},
+	}
+	for _, test := range tests {
+		if c := test.v.CompareN(test.u, test.n); c != test.c {
+			t.Errorf("%v.CompareN(%v, %d): expected %d, got %d", test.v, test.u, test.n, test.c, c)
+		}
+	}
+	for _, n := range []int{0, 5} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("CompareN(%d): expected panic", n)
+				}
+			}()
+			Version{}.CompareN(Version{}, n)
+		}()
+	}
+}
+
+func TestCrosses(t *testing.T) {
+	tests := []struct {
+		from, to   Version
+		gen, minor bool
+	}{
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 123, 1, 1234567, Comma}, false, false},
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 123, 2, 1, Dot}, false, false},
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 124, 0, 1, Dot}, false, true},
+		{Version{0, 124, 0, 1, Dot}, Version{0, 123, 1, 1234567, Dot}, false, true},
+		{Version{0, 123, 1, 1234567, Dot}, Version{1, 123, 1, 1234567, Dot}, true, true},
+		{Version{1, 0, 0, 0, Dot}, Version{0, 999, 9, 9, Dot}, true, true},
+	}
+	for _, test := range tests {
+		if gen := CrossesGeneration(test.from, test.to); gen != test.gen {
+			t.Errorf("CrossesGeneration(%v, %v): expected %t, got %t", test.from, test.to, test.gen, gen)
+		}
+		if minor := CrossesMinor(test.from, test.to); minor != test.minor {
+			t.Errorf("CrossesMinor(%v, %v): expected %t, got %t", test.from, test.to, test.minor, minor)
+		}
+	}
+}
+
+func TestParseGUIDSuffixed(t *testing.T) {
+	const guid = "{01234567-89ab-CDEF-0123-456789abcdef}"
+	tests := []struct {
+		s string
+		v Version
+	}{
+		{"0.123.1.1234567_" + guid, Version{0, 123, 1, 1234567, Dot}},
+		{"0, 123, 1, 1234567_" + guid, Version{0, 123, 1, 1234567, Comma}},
+	}
+	for _, test := range tests {
+		if v, g, err := ParseGUIDSuffixed(test.s); v != test.v || g != guid || err != nil {
+			t.Errorf("ParseGUIDSuffixed(%q): expected (%v, %q, %v), got (%v, %q, %v)", test.s, test.v, guid, nil, v, g, err)
+		}
+	}
+	for _, s := range []string{
+		"0.123.1.1234567",
+		"0.123.1.1234567_",
+		"0.123.1.1234567_{",
+		"0.123.1.1234567_{}",
+		"0.123.1.1234567_01234567-89ab-cdef-0123-456789abcdef",
+		"0.123.1.1234567_{01234567-89ab-cdef-0123-456789abcdef",
+		"0.123.1.1234567_{01234567-89ab-cdef-0123-456789abcdef}x",
+		"0.123.1.1234567_{01234567-89ab-cdef-0123-456789abcde}",
+		"0.123.1.1234567_{01234567089ab-cdef-0123-456789abcdef}",
+		"0.123.1.1234567_{0123456g-89ab-cdef-0123-456789abcdef}",
+		"0.123.1_" + guid,
+		"0.123.1.1234567x_" + guid,
+	} {
+		if v, g, err := ParseGUIDSuffixed(s); v != (Version{}) || g != "" || !errors.Is(err, ErrSyntax) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("ParseGUIDSuffixed(%q): expected error, got (%v, %q, %v)", s, v, g, err)
+		}
+	}
+}
+
+func TestFormatter(t *testing.T) {
+	v := Version{0, 123, 1, 1234567, Dot}
+	c := Version{0, 123, 1, 1234567, Comma}
+	tests := []struct {
+		format string
+		v      Version
+		s      string
+	}{
+		{"%v", v, "0.123.1.1234567"},
+		{"%s", v, "0.123.1.1234567"},
+		{"%v", c, "0, 123, 1, 1234567"},
+		{"%q", v, `"0.123.1.1234567"`},
+		{"%d", c, "0 123 1 1234567"},
+		{"%+v", v, "{Generation:0 Version:123 Patch:1 Commit:1234567 Format:1}"},
+		{"%#v", v, "rbxver.Version{Generation:0, Version:123, Patch:1, Commit:1234567, Format:1}"},
+		{"%20v", v, "     0.123.1.1234567"},
+		{"%-20v|", v, "0.123.1.1234567     |"},
+		{"%.7s", v, "0.123.1"},
+		{"%18d", v, "   0 123 1 1234567"},
+		{"%x", v, "{0 7b 1 12d687 1}"},
+	}
+	for _, test := range tests {
+		if s := fmt.Sprintf(test.format, test.v.Formatter()); s != test.s {
+			t.Errorf("Sprintf(%q, %v): expected %q, got %q", test.format, test.v, test.s, s)
+		}
+	}
+}
+
+func TestAppendText(t *testing.T) {
+	buf := []byte("v=")
+	for _, v := range []Version{
+		{0, 0, 0, 0, Any},
+		{0, 123, 1, 1234567, Dot},
+		{0, 123, 1, 1234567, Comma},
+		{-1, 2, -3, 4, Comma},
+		{math.MaxInt, 0, 0, 0, Dot},
+		{1, 2, 3, 4, 3},
+	} {
+		b, err := v.AppendText(buf[:2])
+		if string(b) != "v="+v.String() || err != nil {
+			t.Errorf("%#v.AppendText(%q): expected (%q, %v), got (%q, %v)", v, "v=", "v="+v.String(), nil, b, err)
+		}
+		buf = b
+		for _, f := range []Format{Any, Dot, Comma} {
+			u := v
+			u.Format = f
+			if b := v.AppendFormat(nil, f); string(b) != u.String() {
+				t.Errorf("%#v.AppendFormat(nil, %s): expected %q, got %q", v, fmtstr[f], u.String(), b)
+			}
+		}
+	}
+	v := Version{0, 123, 1, 1234567, Comma}
+	if n := testing.AllocsPerRun(100, func() { buf, _ = v.AppendText(buf[:0]) }); n != 0 {
+		t.Errorf("AppendText: expected 0 allocations, got %v", n)
+	}
+}
+
+func TestDelta(t *testing.T) {
+	tests := []struct {
+		v, base Version
+		d       [4]int
+	}{
+		{Version{0, 123, 1, 1234567, Dot}, Version{0, 123, 1, 1234567, Dot}, [4]int{0, 0, 0, 0}},
+		{Version{0, 123, 1, 1234600, Dot}, Version{0, 123, 1, 1234567, Dot}, [4]int{0, 0, 0, 33}},
+		{Version{0, 124, 0, 1, Dot}, Version{0, 123, 1, 1234567, Dot}, [4]int{0, 1, -1, -1234566}},
+		{Version{0, 122, 5, 1234567, Dot}, Version{1, 123, 1, 1234567, Dot}, [4]int{-1, -1, 4, 0}},
+	}
+	for _, test := range tests {
+		d := test.v.DeltaFrom(test.base)
+		if d != test.d {
+			t.Errorf("%v.DeltaFrom(%v): expected %v, got %v", test.v, test.base, test.d, d)
+		}
+		if v := test.base.ApplyDelta(d); v != test.v {
+			t.Errorf("%v.ApplyDelta(%v): expected %v, got %v", test.base, d, test.v, v)
+		}
+	}
+	if v := (Version{0, 123, 1, 1234567, Comma}).ApplyDelta([4]int{0, 1, -1, 1}); v != (Version{0, 124, 0, 1234568, Comma}) {
+		t.Errorf("ApplyDelta: expected %v, got %v", Version{0, 124, 0, 1234568, Comma}, v)
+	}
+}
+
+func TestFlagValue(t *testing.T) {
+	var v Version
+	var _ flag.Value = &v
+	if s := v.String(); s != "0.0.0.0" {
+		t.Errorf("String(): expected %q, got %q", "0.0.0.0", s)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(&v, "min-version", "minimum version")
+	if err := fs.Parse([]string{"--min-version", "0, 123, 1, 1234567"}); err != nil {
+		t.Fatalf("Parse: unexpected error %v", err)
+	}
+	if v != (Version{0, 123, 1, 1234567, Comma}) {
+		t.Errorf("Parse: expected %v, got %v", Version{0, 123, 1, 1234567, Comma}, v)
+	}
+
+	for _, s := range []string{"", "0.123.1", "0.123.1.x", "0.123.1.1234567 "} {
+		u := Version{0, 1, 0, 0, Dot}
+		if err := u.Set(s); !errors.Is(err, ErrSyntax) || u != (Version{0, 1, 0, 0, Dot}) {
+			t.Errorf("Set(%q): expected error %v and unchanged version, got %v and %v", s, ErrSyntax, err, u)
+		}
+	}
+	if err := v.Set("0.123.1.99999999999999999999"); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Set(overflow): expected error %v, got %v", ErrOverflow, err)
+	}
+	if err := fs.Parse([]string{"--min-version", "invalid"}); err == nil || !strings.Contains(err.Error(), "invalid syntax at position 0") {
+		t.Errorf("Parse(invalid): expected syntax error, got %v", err)
+	}
+}
+
+func TestParseGeneric(t *testing.T) {
+	gen, ver, patch, commit, err := ParseGeneric[int32]("0.123.1.1234567", Any)
+	if gen != 0 || ver != 123 || patch != 1 || commit != 1234567 || err != nil {
+		t.Errorf("ParseGeneric[int32]: expected (0, 123, 1, 1234567, %v), got (%d, %d, %d, %d, %v)", nil, gen, ver, patch, commit, err)
+	}
+	if _, _, _, _, err := ParseGeneric[int32]("0.123.1.2147483648", Any); !errors.Is(err, ErrOverflow) || err.Error() != "value out of range in Commit" {
+		t.Errorf("ParseGeneric[int32](overflow): expected error %q, got %v", "value out of range in Commit", err)
+	}
+	if _, _, _, commit, err := ParseGeneric[int32]("0.123.1.2147483647", Any); commit != math.MaxInt32 || err != nil {
+		t.Errorf("ParseGeneric[int32](max): expected (%d, %v), got (%d, %v)", math.MaxInt32, nil, commit, err)
+	}
+
+	gen16, ver16, patch16, commit16, err := ParseGeneric[uint16]("1, 2, 3, 65535", Comma)
+	if gen16 != 1 || ver16 != 2 || patch16 != 3 || commit16 != 65535 || err != nil {
+		t.Errorf("ParseGeneric[uint16]: expected (1, 2, 3, 65535, %v), got (%d, %d, %d, %d, %v)", nil, gen16, ver16, patch16, commit16, err)
+	}
+	for i, s := range []string{"65536.0.0.0", "0.65536.0.0", "0.0.65536.0", "0.0.0.1234567"} {
+		want := "value out of range in " + componentNames[i]
+		if gen, ver, patch, commit, err := ParseGeneric[uint16](s, Dot); gen != 0 || ver != 0 || patch != 0 || commit != 0 || !errors.Is(err, ErrOverflow) || err.Error() != want {
+			t.Errorf("ParseGeneric[uint16](%q): expected error %q, got (%d, %d, %d, %d, %v)", s, want, gen, ver, patch, commit, err)
+		}
+	}
+	if _, _, _, _, err := ParseGeneric[uint16]("0.123.x.1", Dot); !errors.Is(err, ErrSyntax) {
+		t.Errorf("ParseGeneric[uint16](invalid): expected error %v, got %v", ErrSyntax, err)
+	}
+}
+
+func TestInSupportWindow(t *testing.T) {
+	latest := Version{0, 123, 1, 1234567, Dot}
+	tests := []struct {
+		v          Version
+		minorsBack int
+		in         bool
+	}{
+		{Version{0, 123, 1, 1234567, Dot}, 0, true},
+		{Version{0, 123, 9, 9999999, Dot}, 0, true},
+		{Version{0, 122, 9, 9999999, Dot}, 0, false},
+		{Version{0, 121, 0, 0, Comma}, 2, true},
+		{Version{0, 120, 9, 9999999, Dot}, 2, false},
+		{Version{0, 124, 0, 0, Dot}, 2, false},
+		{Version{1, 123, 1, 1234567, Dot}, 2, false},
+		{Version{0, 123, 1, 1234567, Dot}, -1, false},
+	}
+	for _, test := range tests {
+		if in := test.v.InSupportWindow(latest, test.minorsBack); in != test.in {
+			t.Errorf("%v.InSupportWindow(%v, %d): expected %t, got %t", test.v, latest, test.minorsBack, test.in, in)
+		}
+	}
+}
+
+func TestScanner(t *testing.T) {
+	tests := []struct {
+		line   string
+		format string
+		f      Format
+		v      Version
+		rest   string
+	}{
+		{"build 0.123.1.1234567 done", "build %v %s", Dot, Version{0, 123, 1, 1234567, Dot}, "done"},
+		{"build 0.123.1.1234567. done", "build %v. %s", Dot, Version{0, 123, 1, 1234567, Dot}, "done"},
+		{"build   0, 123, 1, 1234567, done", "build %v, %s", Comma, Version{0, 123, 1, 1234567, Comma}, "done"},
+		{"build 0, 123, 1, 1234567, done", "build %s, %s", Any, Version{0, 123, 1, 1234567, Comma}, "done"},
+		{"build 0.123.1.1234567,done", "build %v,%s", Any, Version{0, 123, 1, 1234567, Dot}, "done"},
+	}
+	for _, test := range tests {
+		var v Version
+		var rest string
+		n, err := fmt.Sscanf(test.line, test.format, v.Scanner(test.f), &rest)
+		if n != 2 || err != nil || v != test.v || rest != test.rest {
+			t.Errorf("Sscanf(%q, %q): expected (2, %v, %v, %q), got (%d, %v, %v, %q)", test.line, test.format, nil, test.v, test.rest, n, err, v, rest)
+		}
+	}
+
+	errTests := []struct {
+		line string
+		f    Format
+		err  error
+	}{
+		{"build 0, 123, 1, 1234567", Dot, ErrSyntax},
+		{"build 0.123.1.1234567", Comma, ErrSyntax},
+		{"build 0,123,1,1234567", Comma, ErrSyntax},
+		{"build 0.123, 1.1234567", Any, ErrSyntax},
+		{"build x", Dot, ErrSyntax},
+		{"build 0.123.1", Dot, io.ErrUnexpectedEOF},
+		{"build 0.123.1.99999999999999999999", Dot, ErrOverflow},
+	}
+	for _, test := range errTests {
+		v := Version{0, 1, 0, 0, Dot}
+		if _, err := fmt.Sscanf(test.line, "build %v", v.Scanner(test.f)); !errors.Is(err, test.err) || v != (Version{0, 1, 0, 0, Dot}) {
+			t.Errorf("Sscanf(%q, %s): expected error %v and unchanged version, got %v and %v", test.line, fmtstr[test.f], test.err, err, v)
+		}
+	}
+	var v Version
+	if _, err := fmt.Sscanf("0.123.1.1234567", "%d", v.Scanner(Dot)); err == nil {
+		t.Errorf("Sscanf(%%d): expected error, got %v", err)
+	}
+}
+
+func TestParseRegistryValue(t *testing.T) {
+	tests := []struct {
+		s string
+		v Version
+	}{
+		{"0.123.1.1234567", Version{0, 123, 1, 1234567, Dot}},
+		{`"0.123.1.1234567"`, Version{0, 123, 1, 1234567, Dot}},
+		{`  "0, 123, 1, 1234567"` + "\r\n", Version{0, 123, 1, 1234567, Comma}},
+		{"    version    REG_SZ    0.123.1.1234567", Version{0, 123, 1, 1234567, Dot}},
+		{"    version    REG_SZ    \"0.123.1.1234567\"", Version{0, 123, 1, 1234567, Dot}},
+		{"version\tREG_SZ\t0, 123, 1, 1234567\r\n", Version{0, 123, 1, 1234567, Comma}},
+	}
+	for _, test := range tests {
+		if v, err := ParseRegistryValue(test.s); v != test.v || err != nil {
+			t.Errorf("ParseRegistryValue(%q): expected (%v, %v), got (%v, %v)", test.s, test.v, nil, v, err)
+		}
+	}
+	for _, s := range []string{
+		"version-0123456789abcdef",
+		`"version-0123456789abcdef"`,
+		"    version    REG_SZ    version-0123456789abcdef",
+		"version-abc",
+		"version-0123456789abcdeg",
+		`"version-"`,
+	} {
+		if v, err := ParseRegistryValue(s); v != (Version{}) || !errors.Is(err, ErrHash) {
+			t.Errorf("ParseRegistryValue(%q): expected error %v, got (%v, %v)", s, ErrHash, v, err)
+		}
+	}
+	for _, s := range []string{
+		"",
+		`""`,
+		`"0.123.1.1234567`,
+		"0.123.1.1234567\"",
+		"versions-0123456789abcdef",
+		"    version    REG_SZ",
+		"    version    REG_DWORD    0x1",
+		"0.123.1",
+	} {
+		if v, err := ParseRegistryValue(s); v != (Version{}) || err == nil || errors.Is(err, ErrHash) {
+			t.Errorf("ParseRegistryValue(%q): expected parse error, got (%v, %v)", s, v, err)
+		}
+	}
+}